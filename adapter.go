@@ -0,0 +1,38 @@
+package axios4go
+
+import "net/http"
+
+// Adapter sends a fully built request and returns the origin's response,
+// like an axios adapter. Setting Client.Adapter swaps the HTTP engine, for
+// example for a mock, a recorder or another client library, while options,
+// interceptors, auth, caching, logging and status validation still run in
+// the client. The request's context carries the request's Timeout as a
+// deadline. Options that configure net/http, such as Proxy, TLS,
+// MaxRedirects and the phase timeouts, are up to the adapter to honor.
+type Adapter interface {
+	Do(req *http.Request, options *RequestOptions) (*http.Response, error)
+}
+
+// AdapterFunc adapts a function to the Adapter interface.
+type AdapterFunc func(req *http.Request, options *RequestOptions) (*http.Response, error)
+
+func (f AdapterFunc) Do(req *http.Request, options *RequestOptions) (*http.Response, error) {
+	return f(req, options)
+}
+
+// NewHTTPClientAdapter returns an Adapter that sends requests with
+// httpClient as is, without the per-request transport, redirect and
+// timeout settings the client applies when no Adapter is set.
+func NewHTTPClientAdapter(httpClient *http.Client) Adapter {
+	return httpClientAdapter{client: httpClient}
+}
+
+// httpClientAdapter is the default adapter, sending requests with an
+// http.Client.
+type httpClientAdapter struct {
+	client *http.Client
+}
+
+func (a httpClientAdapter) Do(req *http.Request, _ *RequestOptions) (*http.Response, error) {
+	return a.client.Do(req)
+}
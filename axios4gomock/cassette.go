@@ -0,0 +1,296 @@
+package axios4gomock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rezmoss/axios4go"
+)
+
+// CassetteMode selects whether a Cassette sends requests or replays them.
+type CassetteMode int
+
+const (
+	// ModeAuto records when the cassette file doesn't exist yet and
+	// replays otherwise.
+	ModeAuto CassetteMode = iota
+	// ModeRecord always sends requests and overwrites the cassette.
+	ModeRecord
+	// ModeReplay only replays and fails requests that weren't recorded.
+	ModeReplay
+)
+
+// ErrNoInteraction is returned in replay mode for requests the cassette
+// has no recording of.
+var ErrNoInteraction = errors.New("axios4gomock: no recorded interaction matches the request")
+
+const scrubbed = "[SCRUBBED]"
+
+var defaultScrubHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// CassetteOptions configures a Cassette. Requests match a recording with
+// the same method, URL and body, and the same values of the MatchHeaders.
+// The values of the Scrub headers (by default Authorization,
+// Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key) are never written
+// to the file, nor are a password in the URL or an API key sent in the query
+// (see axios4go.MaskedURL), and ScrubFunc can remove other secrets, such as tokens in
+// bodies, before an interaction is saved. Transport sends requests while
+// recording and defaults to http.DefaultTransport.
+type CassetteOptions struct {
+	Path         string
+	Mode         CassetteMode
+	MatchHeaders []string
+	Scrub        []string
+	ScrubFunc    func(*Interaction)
+	Transport    http.RoundTripper
+}
+
+// Interaction is a recorded request and its response. Bodies that aren't
+// valid UTF-8 are stored base64 encoded, with BodyEncoding "base64".
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status       int         `json:"status"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Cassette is an http.RoundTripper that records real responses to a JSON
+// file on the first run and replays them on later runs, so integration
+// tests become hermetic and deterministic. A Cassette is safe for
+// concurrent use.
+type Cassette struct {
+	options   CassetteOptions
+	recording bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette opens the cassette at options.Path, or prepares to record it.
+func NewCassette(options CassetteOptions) (*Cassette, error) {
+	if options.Path == "" {
+		return nil, fmt.Errorf("%w: cassette needs a Path", axios4go.ErrInvalidOption)
+	}
+	if options.Scrub == nil {
+		options.Scrub = defaultScrubHeaders
+	}
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+	c := &Cassette{options: options, recording: options.Mode == ModeRecord}
+	if c.recording {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(options.Path)
+	if errors.Is(err, fs.ErrNotExist) && options.Mode == ModeAuto {
+		c.recording = true
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("axios4gomock: reading cassette %s: %w", options.Path, err)
+	}
+	c.interactions = file.Interactions
+	c.used = make([]bool, len(file.Interactions))
+	return c, nil
+}
+
+// Recording reports whether the cassette sends requests and records them.
+func (c *Cassette) Recording() bool {
+	return c.recording
+}
+
+// Install makes client send its requests through c, keeping the rest of
+// its HTTPClient settings.
+func (c *Cassette) Install(client *axios4go.Client) {
+	httpClient := &http.Client{}
+	if client.HTTPClient != nil {
+		*httpClient = *client.HTTPClient
+	}
+	httpClient.Transport = c
+	client.HTTPClient = httpClient
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.recording {
+		return c.record(req, body)
+	}
+	return c.replay(req, body)
+}
+
+// Do lets c be used as a Client.Adapter.
+func (c *Cassette) Do(req *http.Request, _ *axios4go.RequestOptions) (*http.Response, error) {
+	return c.RoundTrip(req)
+}
+
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := c.options.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     axios4go.MaskedURL(req),
+			Headers: c.scrub(req.Header),
+		},
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: c.scrub(resp.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(body)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(respBody)
+	if c.options.ScrubFunc != nil {
+		c.options.ScrubFunc(&interaction)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction)
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	header := c.scrub(req.Header)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Interactions are replayed in the order they were recorded; once all
+	// matching ones are used, the last one is repeated.
+	match := -1
+	for i, interaction := range c.interactions {
+		if !c.matches(interaction.Request, req, header, body) {
+			continue
+		}
+		match = i
+		if !c.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, axios4go.MaskedURL(req))
+	}
+	c.used[match] = true
+
+	recorded := c.interactions[match].Response
+	respBody, err := decodeBody(recorded.Body, recorded.BodyEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return response(req, recorded.Status, recorded.Headers.Clone(), respBody), nil
+}
+
+func (c *Cassette) matches(recorded RecordedRequest, req *http.Request, header http.Header, body []byte) bool {
+	if recorded.Method != req.Method || recorded.URL != axios4go.MaskedURL(req) {
+		return false
+	}
+	recordedBody, err := decodeBody(recorded.Body, recorded.BodyEncoding)
+	if err != nil || !bytes.Equal(recordedBody, body) {
+		return false
+	}
+	for _, name := range c.options.MatchHeaders {
+		if strings.Join(recorded.Headers.Values(name), ",") != strings.Join(header.Values(name), ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// scrub returns a copy of header with the values of the Scrub headers
+// replaced.
+func (c *Cassette) scrub(header http.Header) http.Header {
+	clone := header.Clone()
+	for _, name := range c.options.Scrub {
+		if clone.Get(name) != "" {
+			clone.Set(name, scrubbed)
+		}
+	}
+	return clone
+}
+
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.options.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.options.Path, data, 0o644)
+}
+
+// readBody reads req's body and replaces it, so it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
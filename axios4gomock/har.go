@@ -0,0 +1,111 @@
+package axios4gomock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rezmoss/axios4go"
+)
+
+// LoadHAR adds a route for every entry of har, such as a session exported
+// from browser devtools or recorded with an axios4go HARRecorder, answering
+// the entry's method and full URL with its response. Entries that failed
+// without a response reply with their error. Requests to the same URL are
+// answered in the order they were captured, and the last answer repeats.
+func (m *Mock) LoadHAR(har *axios4go.HAR) error {
+	remaining := map[string]int{}
+	for _, entry := range har.Log.Entries {
+		remaining[harKey(entry)]++
+	}
+	for i, entry := range har.Log.Entries {
+		route := m.On(entry.Request.Method, entry.Request.URL)
+		if remaining[harKey(entry)]--; remaining[harKey(entry)] > 0 {
+			route.Times(1)
+		}
+		if entry.Response.Status == 0 {
+			message := entry.Error
+			if message == "" {
+				message = "captured request failed without a response"
+			}
+			route.ReplyError(errors.New(message))
+			continue
+		}
+		body, err := decodeBody(entry.Response.Content.Text, entry.Response.Content.Encoding)
+		if err != nil {
+			return fmt.Errorf("axios4gomock: HAR entry %d: %w", i, err)
+		}
+		header := harHeader(entry.Response.Headers)
+		status := entry.Response.Status
+		route.setReply(func(req *http.Request) (*http.Response, error) {
+			return response(req, status, header.Clone(), body), nil
+		})
+	}
+	return nil
+}
+
+// CassetteFromHAR returns a Cassette that replays the entries of har that
+// have a response, matching requests by method, URL, body and
+// options.MatchHeaders. If options.Path is set, the interactions are saved
+// there, so a captured session can be checked in as a cassette.
+func CassetteFromHAR(har *axios4go.HAR, options CassetteOptions) (*Cassette, error) {
+	if options.Scrub == nil {
+		options.Scrub = defaultScrubHeaders
+	}
+	options.Mode = ModeReplay
+	c := &Cassette{options: options}
+	for _, entry := range har.Log.Entries {
+		if entry.Response.Status == 0 {
+			continue
+		}
+		interaction := Interaction{
+			Request: RecordedRequest{
+				Method:  entry.Request.Method,
+				URL:     entry.Request.URL,
+				Headers: c.scrub(harHeader(entry.Request.Headers)),
+			},
+			Response: RecordedResponse{
+				Status:       entry.Response.Status,
+				Headers:      c.scrub(harHeader(entry.Response.Headers)),
+				Body:         entry.Response.Content.Text,
+				BodyEncoding: entry.Response.Content.Encoding,
+			},
+		}
+		if entry.Request.PostData != nil {
+			interaction.Request.Body = entry.Request.PostData.Text
+		}
+		if options.ScrubFunc != nil {
+			options.ScrubFunc(&interaction)
+		}
+		c.interactions = append(c.interactions, interaction)
+	}
+	c.used = make([]bool, len(c.interactions))
+	if options.Path != "" {
+		if err := c.save(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func harKey(entry axios4go.HAREntry) string {
+	return entry.Request.Method + " " + entry.Request.URL
+}
+
+// harHeader converts HAR headers, dropping HTTP/2 pseudo-headers and the
+// encoding headers, since HAR bodies are stored decoded.
+func harHeader(values []axios4go.HARNameValue) http.Header {
+	header := http.Header{}
+	for _, v := range values {
+		if strings.HasPrefix(v.Name, ":") {
+			continue
+		}
+		switch http.CanonicalHeaderKey(v.Name) {
+		case "Content-Encoding", "Content-Length", "Transfer-Encoding":
+			continue
+		}
+		header.Add(v.Name, v.Value)
+	}
+	return header
+}
@@ -0,0 +1,337 @@
+// Package axios4gomock answers the requests of an axios4go client from
+// registered routes instead of the network, like axios-mock-adapter, so
+// tests don't need an httptest server:
+//
+//	mock := axios4gomock.New()
+//	mock.On("GET", "/users/*").Reply(200, map[string]string{"name": "ann"})
+//	mock.Install(client)
+//
+//	// ... code under test sends requests through client ...
+//
+//	mock.AssertCalled(t, "GET", "/users/*", 1)
+package axios4gomock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/rezmoss/axios4go"
+)
+
+// ErrNoRoute is returned for requests that match no route.
+var ErrNoRoute = errors.New("axios4gomock: no route matches the request")
+
+// TestingT is the part of *testing.T the assertions use.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call is a request a Mock received.
+type Call struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Mock is an http.RoundTripper that answers requests from its routes. Routes
+// are tried in the order they were registered. A Mock is safe for
+// concurrent use.
+type Mock struct {
+	mu     sync.Mutex
+	routes []*Route
+	calls  []Call
+}
+
+// Route answers the requests matching a method and URL pattern. Method ""
+// or "*" matches any method. A pattern starting with "/" is matched
+// against the URL path, and against the path and query if it contains
+// "?"; any other pattern is matched against the full URL. Patterns use
+// path.Match syntax, so "*" matches within one path segment.
+type Route struct {
+	method  string
+	pattern string
+	mock    *Mock
+	reply   func(*http.Request) (*http.Response, error)
+	times   int
+	calls   []Call
+}
+
+func New() *Mock {
+	return &Mock{}
+}
+
+// Install makes client send its requests through m, keeping the rest of
+// its HTTPClient settings.
+func (m *Mock) Install(client *axios4go.Client) {
+	httpClient := &http.Client{}
+	if client.HTTPClient != nil {
+		*httpClient = *client.HTTPClient
+	}
+	httpClient.Transport = m
+	client.HTTPClient = httpClient
+}
+
+// On registers a route. Until a reply is set it answers with 200 and no
+// body.
+func (m *Mock) On(method, pattern string) *Route {
+	route := &Route{method: strings.ToUpper(method), pattern: pattern, mock: m}
+	route.Reply(http.StatusOK, nil)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = append(m.routes, route)
+	return route
+}
+
+// Reply answers with status and body, which is sent as is if it is a
+// string or []byte and encoded as JSON otherwise. headers are added to the
+// response.
+func (r *Route) Reply(status int, body interface{}, headers ...map[string]string) *Route {
+	header := make(http.Header)
+	var data []byte
+	switch v := body.(type) {
+	case nil:
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return r.ReplyError(fmt.Errorf("axios4gomock: encoding reply: %w", err))
+		}
+		data = encoded
+		header.Set("Content-Type", "application/json")
+	}
+	for _, h := range headers {
+		for key, value := range h {
+			header.Set(key, value)
+		}
+	}
+	return r.setReply(func(req *http.Request) (*http.Response, error) {
+		return response(req, status, header.Clone(), data), nil
+	})
+}
+
+// ReplyError fails matching requests with err, as if the network did.
+func (r *Route) ReplyError(err error) *Route {
+	return r.setReply(func(*http.Request) (*http.Response, error) {
+		return nil, err
+	})
+}
+
+// Handle answers with handler, such as one from the fixtures package.
+func (r *Route) Handle(handler http.Handler) *Route {
+	return r.setReply(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return response(req, recorder.Code, recorder.Header(), recorder.Body.Bytes()), nil
+	})
+}
+
+// Times limits the route to the next n matching requests, if n is
+// positive; later requests fall through to the routes registered after it.
+func (r *Route) Times(n int) *Route {
+	r.mock.mu.Lock()
+	defer r.mock.mu.Unlock()
+	r.times = n
+	return r
+}
+
+// Calls returns the requests the route answered.
+func (r *Route) Calls() []Call {
+	r.mock.mu.Lock()
+	defer r.mock.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+func (r *Route) setReply(reply func(*http.Request) (*http.Response, error)) *Route {
+	r.mock.mu.Lock()
+	defer r.mock.mu.Unlock()
+	r.reply = reply
+	return r
+}
+
+func (r *Route) matches(req *http.Request) bool {
+	if r.method != "" && r.method != "*" && r.method != req.Method {
+		return false
+	}
+	return matchURL(r.pattern, req)
+}
+
+func matchURL(pattern string, req *http.Request) bool {
+	target := req.URL.String()
+	if strings.HasPrefix(pattern, "/") {
+		target = req.URL.Path
+		if strings.Contains(pattern, "?") {
+			target = req.URL.RequestURI()
+		}
+	}
+	if target == pattern {
+		return true
+	}
+	matched, err := path.Match(pattern, target)
+	return err == nil && matched
+}
+
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	call := Call{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: body}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	var route *Route
+	for _, candidate := range m.routes {
+		if candidate.times >= 0 && candidate.matches(req) {
+			route = candidate
+			break
+		}
+	}
+	if route == nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrNoRoute, req.Method, req.URL.Redacted())
+	}
+	route.calls = append(route.calls, call)
+	if route.times > 0 {
+		if route.times--; route.times == 0 {
+			route.times = -1
+		}
+	}
+	reply := route.reply
+	m.mu.Unlock()
+
+	return reply(req)
+}
+
+// Do lets m be used as a Client.Adapter.
+func (m *Mock) Do(req *http.Request, _ *axios4go.RequestOptions) (*http.Response, error) {
+	return m.RoundTrip(req)
+}
+
+// Calls returns every request m received, including unmatched ones.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Reset removes all routes and recorded calls.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = nil
+	m.calls = nil
+}
+
+// matching returns the received requests that match method and pattern,
+// whichever route answered them.
+func (m *Mock) matching(method, pattern string) []Call {
+	probe := &Route{method: strings.ToUpper(method), pattern: pattern}
+	var calls []Call
+	for _, call := range m.Calls() {
+		req, err := http.NewRequest(call.Method, call.URL, nil)
+		if err == nil && probe.matches(req) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// AssertCalled checks that exactly times requests matched method and
+// pattern.
+func (m *Mock) AssertCalled(t TestingT, method, pattern string, times int) bool {
+	t.Helper()
+	if calls := m.matching(method, pattern); len(calls) != times {
+		t.Errorf("axios4gomock: expected %d calls to %s %s, got %d", times, method, pattern, len(calls))
+		return false
+	}
+	return true
+}
+
+// AssertBody checks the body of the last request matching method and
+// pattern. A string or []byte want is compared as is; anything else is
+// compared as JSON, ignoring formatting and key order.
+func (m *Mock) AssertBody(t TestingT, method, pattern string, want interface{}) bool {
+	t.Helper()
+	calls := m.matching(method, pattern)
+	if len(calls) == 0 {
+		t.Errorf("axios4gomock: expected a call to %s %s, got none", method, pattern)
+		return false
+	}
+	got := calls[len(calls)-1].Body
+
+	switch v := want.(type) {
+	case string:
+		if string(got) == v {
+			return true
+		}
+	case []byte:
+		if bytes.Equal(got, v) {
+			return true
+		}
+	default:
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("axios4gomock: encoding expected body: %v", err)
+			return false
+		}
+		if jsonEqual(got, expected) {
+			return true
+		}
+		want = string(expected)
+	}
+	t.Errorf("axios4gomock: expected body %s for %s %s, got %s", want, method, pattern, got)
+	return false
+}
+
+// AssertAllCalled checks that every route answered at least one request.
+func (m *Mock) AssertAllCalled(t TestingT) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ok := true
+	for _, route := range m.routes {
+		if len(route.calls) == 0 {
+			t.Errorf("axios4gomock: route %s %s was never called", route.method, route.pattern)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	na, _ := json.Marshal(va)
+	nb, _ := json.Marshal(vb)
+	return bytes.Equal(na, nb)
+}
+
+func response(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,295 @@
+package axios4gomock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rezmoss/axios4go"
+	"github.com/rezmoss/axios4go/fixtures"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMock(t *testing.T) {
+	mock := New()
+	mock.On("GET", "/users/*").Reply(http.StatusOK, map[string]string{"name": "ann"}, map[string]string{"X-Total": "1"})
+	mock.On("POST", "/users").Reply(http.StatusCreated, "created")
+	client := axios4go.NewClient("https://api.example.com")
+	mock.Install(client)
+
+	resp, err := client.Request(&axios4go.RequestOptions{URL: "/users/1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != `{"name":"ann"}` || resp.Headers.Get("X-Total") != "1" {
+		t.Errorf("Unexpected response %d %s %v", resp.StatusCode, resp.Body, resp.Headers)
+	}
+	resp, err = client.Request(&axios4go.RequestOptions{Method: "POST", URL: "/users", Body: map[string]interface{}{"name": "bob", "age": 3}})
+	if err != nil || resp.StatusCode != http.StatusCreated || string(resp.Body) != "created" {
+		t.Errorf("Unexpected response %v, %v", resp, err)
+	}
+
+	if !mock.AssertCalled(t, "GET", "/users/*", 1) || !mock.AssertCalled(t, "POST", "/users", 1) {
+		return
+	}
+	mock.AssertBody(t, "POST", "/users", map[string]interface{}{"age": 3, "name": "bob"})
+	mock.AssertAllCalled(t)
+
+	t.Run("Failing Assertions", func(t *testing.T) {
+		rt := &recordingT{}
+		mock.AssertCalled(rt, "GET", "/users/*", 2)
+		mock.AssertBody(rt, "POST", "/users", `{"name":"carl"}`)
+		mock.On("DELETE", "/users/*")
+		mock.AssertAllCalled(rt)
+		if len(rt.errors) != 3 {
+			t.Errorf("Expected 3 failures, got %q", rt.errors)
+		}
+	})
+
+	t.Run("No Route", func(t *testing.T) {
+		_, err := client.Request(&axios4go.RequestOptions{URL: "/orders"})
+		if !errors.Is(err, ErrNoRoute) {
+			t.Errorf("Expected ErrNoRoute, got %v", err)
+		}
+	})
+}
+
+func TestMockReplies(t *testing.T) {
+	mock := New()
+	mock.On("GET", "/flaky").Times(2).Reply(http.StatusServiceUnavailable, nil)
+	mock.On("GET", "/flaky").Reply(http.StatusOK, "ok")
+	mock.On("*", "https://auth.example.com/*").Handle(fixtures.BearerAuth("token", fixtures.JSON(http.StatusOK, "ok")))
+	mock.On("GET", "/down").ReplyError(errors.New("connection refused"))
+	client := axios4go.NewClient("")
+	client.Adapter = mock
+
+	for i, want := range []int{503, 503, 200} {
+		resp, err := client.Request(&axios4go.RequestOptions{URL: "https://api.example.com/flaky"})
+		if err != nil || resp.StatusCode != want {
+			t.Errorf("Call %d: expected %d, got %v, %v", i+1, want, resp, err)
+		}
+	}
+
+	resp, err := client.Request(&axios4go.RequestOptions{
+		URL:  "https://auth.example.com/me",
+		Auth: &axios4go.Auth{BearerToken: "token"},
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the handler to accept the token, got %v, %v", resp, err)
+	}
+
+	var reqErr *axios4go.RequestError
+	if _, err := client.Request(&axios4go.RequestOptions{URL: "https://api.example.com/down"}); !errors.As(err, &reqErr) {
+		t.Errorf("Expected a request error, got %v", err)
+	}
+	if calls := mock.Calls(); len(calls) != 5 {
+		t.Errorf("Expected 5 calls, got %d", len(calls))
+	}
+}
+
+func TestCassette(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret-session")
+		fmt.Fprintf(w, "call %d %s %s", calls, r.Header.Get("X-Tenant"), body)
+	}))
+	path := filepath.Join(t.TempDir(), "cassettes", "users.json")
+	send := func(cassette *Cassette, tenant, body string) string {
+		t.Helper()
+		client := axios4go.NewClient(server.URL)
+		cassette.Install(client)
+		resp, err := client.Request(&axios4go.RequestOptions{
+			Method:  "POST",
+			URL:     "/users",
+			Body:    body,
+			Headers: map[string]string{"X-Tenant": tenant, "Authorization": "Bearer secret-token"},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return string(resp.Body)
+	}
+
+	options := CassetteOptions{Path: path, MatchHeaders: []string{"X-Tenant"}}
+	recorder, err := NewCassette(options)
+	if err != nil || !recorder.Recording() {
+		t.Fatalf("Expected a recording cassette, got %v", err)
+	}
+	send(recorder, "a", "one")
+	send(recorder, "a", "one")
+	send(recorder, "b", "one")
+	server.Close()
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), "secret-session") {
+		t.Errorf("Expected secrets to be scrubbed, got %s", data)
+	}
+
+	player, err := NewCassette(options)
+	if err != nil || player.Recording() {
+		t.Fatalf("Expected a replaying cassette, got %v", err)
+	}
+	for _, tc := range []struct{ tenant, want string }{
+		{"b", "call 3 b one"},
+		{"a", "call 1 a one"},
+		{"a", "call 2 a one"},
+		{"a", "call 2 a one"},
+	} {
+		if got := send(player, tc.tenant, "one"); got != tc.want {
+			t.Errorf("Expected %q, got %q", tc.want, got)
+		}
+	}
+
+	client := axios4go.NewClient(server.URL)
+	player.Install(client)
+	if _, err := client.Request(&axios4go.RequestOptions{Method: "POST", URL: "/users", Body: "two"}); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("Expected ErrNoInteraction, got %v", err)
+	}
+
+	if _, err := NewCassette(CassetteOptions{Path: filepath.Join(t.TempDir(), "missing.json"), Mode: ModeReplay}); err == nil {
+		t.Error("Expected an error for a missing cassette in replay mode")
+	}
+
+	t.Run("Query API Key", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.URL.Query().Get("page")))
+		}))
+		path := filepath.Join(t.TempDir(), "query.json")
+		send := func(cassette *Cassette) string {
+			t.Helper()
+			client := axios4go.NewClient(server.URL)
+			cassette.Install(client)
+			resp, err := client.Request(&axios4go.RequestOptions{
+				URL:    "/items",
+				Params: map[string]string{"page": "2"},
+				Auth:   &axios4go.Auth{APIKey: "secret-key", In: "query"},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			return string(resp.Body)
+		}
+
+		recorder, _ := NewCassette(CassetteOptions{Path: path})
+		send(recorder)
+		server.Close()
+
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "secret-key") || !strings.Contains(string(data), "page=2") {
+			t.Errorf("Expected the API key to be scrubbed from the URL, got %s", data)
+		}
+		player, _ := NewCassette(CassetteOptions{Path: path})
+		if got := send(player); got != "2" {
+			t.Errorf("Expected the recording to be replayed, got %q", got)
+		}
+	})
+}
+
+const capturedHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [
+      {
+        "startedDateTime": "2024-05-01T10:00:00.000Z",
+        "request": {"method": "GET", "url": "https://api.example.com/users?page=1", "headers": [{"name": ":authority", "value": "api.example.com"}]},
+        "response": {
+          "status": 200,
+          "headers": [{"name": "content-type", "value": "application/json"}, {"name": "content-encoding", "value": "gzip"}],
+          "content": {"size": 14, "mimeType": "application/json", "text": "[{\"id\":1}]"}
+        },
+        "cache": {"beforeRequest": null},
+        "timings": {"blocked": 1.2, "wait": 30.5, "_blocked_queueing": 0.8}
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:01.000Z",
+        "request": {"method": "GET", "url": "https://api.example.com/users?page=1"},
+        "response": {"status": 304, "content": {"size": 0}}
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:02.000Z",
+        "request": {
+          "method": "POST",
+          "url": "https://api.example.com/users",
+          "headers": [{"name": "Authorization", "value": "Bearer secret-token"}],
+          "postData": {"mimeType": "application/json", "text": "{\"name\":\"ann\"}"}
+        },
+        "response": {"status": 201, "content": {"size": 3, "text": "AQID", "encoding": "base64"}}
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:03.000Z",
+        "request": {"method": "GET", "url": "https://api.example.com/down"},
+        "response": {"status": 0},
+        "_error": "net::ERR_CONNECTION_REFUSED"
+      }
+    ]
+  }
+}`
+
+func TestHAR(t *testing.T) {
+	har, err := axios4go.ReadHAR(strings.NewReader(capturedHAR))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mock := New()
+	if err := mock.LoadHAR(har); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := axios4go.NewClient("https://api.example.com")
+	client.Adapter = mock
+
+	for _, want := range []int{200, 304, 304} {
+		resp, err := client.Request(&axios4go.RequestOptions{URL: "/users", Params: map[string]string{"page": "1"}, ValidateStatus: func(int) bool { return true }})
+		if err != nil || resp.StatusCode != want {
+			t.Fatalf("Expected %d, got %v, %v", want, resp, err)
+		}
+		if want == 200 && (string(resp.Body) != `[{"id":1}]` || resp.Headers.Get("Content-Encoding") != "") {
+			t.Errorf("Unexpected response %s %v", resp.Body, resp.Headers)
+		}
+	}
+	resp, err := client.Request(&axios4go.RequestOptions{Method: "POST", URL: "/users", Body: map[string]string{"name": "ann"}})
+	if err != nil || string(resp.Body) != "\x01\x02\x03" {
+		t.Errorf("Expected the decoded body, got %v, %v", resp, err)
+	}
+	if _, err := client.Request(&axios4go.RequestOptions{URL: "/down"}); err == nil || !strings.Contains(err.Error(), "ERR_CONNECTION_REFUSED") {
+		t.Errorf("Expected the captured error, got %v", err)
+	}
+
+	t.Run("Cassette", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.json")
+		cassette, err := CassetteFromHAR(har, CassetteOptions{Path: path})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if data, _ := os.ReadFile(path); strings.Contains(string(data), "secret-token") {
+			t.Errorf("Expected secrets to be scrubbed, got %s", data)
+		}
+		client := axios4go.NewClient("https://api.example.com")
+		client.Adapter = cassette
+		resp, err := client.Request(&axios4go.RequestOptions{Method: "POST", URL: "/users", Body: map[string]string{"name": "ann"}})
+		if err != nil || resp.StatusCode != http.StatusCreated {
+			t.Errorf("Expected the recorded response, got %v, %v", resp, err)
+		}
+		if _, err := client.Request(&axios4go.RequestOptions{Method: "POST", URL: "/users", Body: map[string]string{"name": "bob"}}); !errors.Is(err, ErrNoInteraction) {
+			t.Errorf("Expected ErrNoInteraction for another body, got %v", err)
+		}
+	})
+}
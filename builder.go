@@ -0,0 +1,147 @@
+package axios4go
+
+import (
+	"fmt"
+	"time"
+)
+
+// RequestBuilder builds and sends a request step by step, as an
+// alternative to a RequestOptions literal:
+//
+//	var user User
+//	resp, err := client.R().
+//		SetHeader("X-Tenant", "acme").
+//		SetBody(map[string]string{"name": "ann"}).
+//		SetResult(&user).
+//		Post("/users")
+//
+// A RequestBuilder sends one request and is not safe for concurrent use.
+type RequestBuilder struct {
+	client  *Client
+	options RequestOptions
+	result  interface{}
+}
+
+// R returns a builder of a request sent with c.
+func (c *Client) R() *RequestBuilder {
+	return &RequestBuilder{client: c}
+}
+
+func (b *RequestBuilder) SetHeader(key, value string) *RequestBuilder {
+	if b.options.Headers == nil {
+		b.options.Headers = make(map[string]string)
+	}
+	b.options.Headers[key] = value
+	return b
+}
+
+// AddHeader adds a value to the header key, which is sent once per value.
+func (b *RequestBuilder) AddHeader(key, value string) *RequestBuilder {
+	b.options.AddHeader(key, value)
+	return b
+}
+
+func (b *RequestBuilder) SetHeaders(headers map[string]string) *RequestBuilder {
+	for key, value := range headers {
+		b.SetHeader(key, value)
+	}
+	return b
+}
+
+func (b *RequestBuilder) SetQueryParam(key, value string) *RequestBuilder {
+	if b.options.Params == nil {
+		b.options.Params = make(map[string]string)
+	}
+	b.options.Params[key] = value
+	return b
+}
+
+func (b *RequestBuilder) SetQueryParams(params map[string]string) *RequestBuilder {
+	for key, value := range params {
+		b.SetQueryParam(key, value)
+	}
+	return b
+}
+
+// SetBody sets the body, which is sent as is if it is a string or []byte
+// and encoded as JSON otherwise.
+func (b *RequestBuilder) SetBody(body interface{}) *RequestBuilder {
+	b.options.Body = body
+	return b
+}
+
+// SetResult makes the request decode the JSON response body into v, a
+// pointer. An empty body leaves v unchanged.
+func (b *RequestBuilder) SetResult(v interface{}) *RequestBuilder {
+	b.result = v
+	return b
+}
+
+func (b *RequestBuilder) SetAuth(auth *Auth) *RequestBuilder {
+	b.options.Auth = auth
+	return b
+}
+
+func (b *RequestBuilder) SetBasicAuth(username, password string) *RequestBuilder {
+	return b.SetAuth(&Auth{Username: username, Password: password})
+}
+
+func (b *RequestBuilder) SetBearerToken(token string) *RequestBuilder {
+	return b.SetAuth(&Auth{BearerToken: token})
+}
+
+func (b *RequestBuilder) SetTimeout(timeout time.Duration) *RequestBuilder {
+	b.options.Timeout = int(timeout.Milliseconds())
+	return b
+}
+
+// SetOptions sets every field that options sets, for the options the
+// builder has no setter for.
+func (b *RequestBuilder) SetOptions(options *RequestOptions) *RequestBuilder {
+	mergeOptions(&b.options, options)
+	return b
+}
+
+func (b *RequestBuilder) Get(url string) (*Response, error) {
+	return b.Send("GET", url)
+}
+
+func (b *RequestBuilder) Post(url string) (*Response, error) {
+	return b.Send("POST", url)
+}
+
+func (b *RequestBuilder) Put(url string) (*Response, error) {
+	return b.Send("PUT", url)
+}
+
+func (b *RequestBuilder) Patch(url string) (*Response, error) {
+	return b.Send("PATCH", url)
+}
+
+func (b *RequestBuilder) Delete(url string) (*Response, error) {
+	return b.Send("DELETE", url)
+}
+
+func (b *RequestBuilder) Head(url string) (*Response, error) {
+	return b.Send("HEAD", url)
+}
+
+func (b *RequestBuilder) Options(url string) (*Response, error) {
+	return b.Send("OPTIONS", url)
+}
+
+// Send sends the request with method to url. If the response body can't be
+// decoded into the result, the response is returned with the error.
+func (b *RequestBuilder) Send(method, url string) (*Response, error) {
+	options := b.options
+	options.Method = method
+	options.URL = url
+	resp, err := b.client.Request(&options)
+	if err != nil || b.result == nil || len(resp.Body) == 0 {
+		return resp, err
+	}
+	if err := resp.JSON(b.result); err != nil {
+		return resp, fmt.Errorf("decoding response body: %w", err)
+	}
+	return resp, nil
+}
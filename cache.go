@@ -0,0 +1,827 @@
+package axios4go
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+	Clear()
+}
+
+// CacheRanger is implemented by caches that can list their entries, which
+// Client.InvalidateCache needs. MemoryCache and DiskCache implement it.
+type CacheRanger interface {
+	Range(fn func(key string, entry *CacheEntry) bool)
+}
+
+// CacheMatcher selects cache entries for Client.InvalidateCache.
+type CacheMatcher func(key string, entry *CacheEntry) bool
+
+// ByTag matches entries stored by requests with the given CacheOptions tag.
+func ByTag(tag string) CacheMatcher {
+	return func(_ string, entry *CacheEntry) bool {
+		for _, t := range entry.Tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ByNamespace matches the entries stored by clients with the given
+// CacheConfig.Namespace.
+func ByNamespace(namespace string) CacheMatcher {
+	return func(key string, _ *CacheEntry) bool {
+		return strings.HasPrefix(key, namespace+":")
+	}
+}
+
+// ByURLPrefix matches entries whose request URL starts with prefix. A prefix
+// starting with "/" is compared with the URL's path and query, anything else
+// with the full URL.
+func ByURLPrefix(prefix string) CacheMatcher {
+	return func(_ string, entry *CacheEntry) bool {
+		if !strings.HasPrefix(prefix, "/") {
+			return strings.HasPrefix(entry.URL, prefix)
+		}
+		u, err := url.Parse(entry.URL)
+		return err == nil && strings.HasPrefix(u.RequestURI(), prefix)
+	}
+}
+
+// CacheEntry is a stored response. Vary lists the request headers named by
+// the response's Vary header; such entries are also stored under a key that
+// includes those headers' values, so differently negotiated responses don't
+// collide.
+//
+// An entry is fresh until StaleAt. Between StaleAt and RevalidateUntil it is
+// still served, but refreshed in the background; after that and until
+// ExpiresAt, when the cache drops it, it only stands in for errors. A zero
+// StaleAt means the entry stays fresh until ExpiresAt.
+type CacheEntry struct {
+	StatusCode      int
+	Headers         http.Header
+	Body            []byte
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+	StaleAt         time.Time
+	RevalidateUntil time.Time
+	Vary            []string
+	URL             string
+	Tags            []string
+}
+
+// CacheMode selects where cache lifetimes come from.
+type CacheMode int
+
+const (
+	// CacheModeTTL caches the GET requests that opt in with
+	// RequestOptions.Cache for the TTL given there or DefaultTTL.
+	CacheModeTTL CacheMode = iota
+	// CacheModeHTTP caches every GET request by the origin's rules
+	// (RFC 7234): lifetimes come from Cache-Control max-age or Expires,
+	// no-store, no-cache and private responses are never stored, and a
+	// request's own Cache-Control no-store or no-cache skips the cache. When
+	// a response carries no lifetime, the request's TTL or DefaultTTL is
+	// used if set, otherwise it is not stored. CacheDisabled() still opts a
+	// request out.
+	CacheModeHTTP
+	// CacheModeOptOut caches every GET request for its TTL, DefaultTTL or
+	// five minutes, unless the request opts out with CacheDisabled().
+	CacheModeOptOut
+)
+
+// CacheConfig configures a client's cache. StaleWhileRevalidate keeps
+// serving an entry for that long after its TTL runs out while a background
+// request refreshes it, so latency-critical reads never wait on the origin.
+// StaleIfError keeps an expired entry for that long to answer in place of a
+// network error or 5xx response. In CacheModeHTTP, a response's own
+// stale-while-revalidate and stale-if-error directives take precedence.
+//
+// TTLJitter spreads expiry times: each entry's TTL is changed by a random
+// amount of up to that fraction (0.1 for ±10%), so entries cached together
+// don't all expire, and hit the origin, at the same moment.
+//
+// Namespace prefixes the client's cache keys with "Namespace:", so several
+// clients can share one Cache without their entries colliding.
+// InvalidateCache and ClearCache then only touch the client's own entries.
+//
+// OnCacheHit, OnCacheMiss and OnCacheStore are called with the cache key,
+// the request URL and, except for misses, the entry, for metrics and debug
+// logging. They run synchronously on the request's goroutine.
+type CacheConfig struct {
+	Cache                Cache
+	DefaultTTL           time.Duration
+	Mode                 CacheMode
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+	TTLJitter            float64
+	Namespace            string
+	OnCacheHit           func(key, urlStr string, entry *CacheEntry)
+	OnCacheMiss          func(key, urlStr string)
+	OnCacheStore         func(key, urlStr string, entry *CacheEntry)
+}
+
+func (c *CacheConfig) hit(key, urlStr string, entry *CacheEntry) {
+	if c.OnCacheHit != nil {
+		c.OnCacheHit(key, urlStr, entry)
+	}
+}
+
+func (c *CacheConfig) miss(key, urlStr string) {
+	if c.OnCacheMiss != nil {
+		c.OnCacheMiss(key, urlStr)
+	}
+}
+
+// CacheOptions configures caching for a request. Tags are stored with the
+// entry so that Client.InvalidateCache(ByTag(...)) can purge it.
+type CacheOptions struct {
+	Enabled bool
+	TTL     time.Duration
+	Key     string
+	Tags    []string
+}
+
+// MemoryCacheOptions configures a MemoryCache. MaxEntries and MaxBytes
+// bound the number and the approximate total size of the entries; when
+// either is exceeded the least recently used entries are evicted. Entries
+// larger than MaxEntryBytes are not stored. When SnapshotFile is set, the
+// cache is warmed from that file on creation and written back to it on
+// Close. Clock, when set, decides when entries expire.
+type MemoryCacheOptions struct {
+	MaxEntries      int
+	MaxBytes        int64
+	MaxEntryBytes   int64
+	CleanupInterval time.Duration
+	SnapshotFile    string
+	Clock           Clock
+
+	// OnError, when set, is called with the errors from reading and
+	// writing SnapshotFile, which the cache can't return itself. A missing
+	// snapshot file is not an error.
+	OnError func(error)
+}
+
+type MemoryCache struct {
+	options   MemoryCacheOptions
+	entries   map[string]*list.Element
+	lru       *list.List
+	size      int64
+	mu        sync.Mutex
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+	size  int64
+}
+
+const defaultCacheTTL = 5 * time.Minute
+
+func (e *CacheEntry) IsExpired() bool {
+	return e.expiredAt(time.Now())
+}
+
+func (e *CacheEntry) IsStale() bool {
+	return e.staleAt(time.Now())
+}
+
+func (e *CacheEntry) Age() time.Duration {
+	return time.Since(e.CreatedAt)
+}
+
+func (e *CacheEntry) expiredAt(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+func (e *CacheEntry) staleAt(now time.Time) bool {
+	return !e.StaleAt.IsZero() && now.After(e.StaleAt)
+}
+
+func (e *CacheEntry) revalidatableAt(now time.Time) bool {
+	return now.Before(e.RevalidateUntil)
+}
+
+func CacheWithTTL(ttl time.Duration) *CacheOptions {
+	return &CacheOptions{Enabled: true, TTL: ttl}
+}
+
+func CacheDisabled() *CacheOptions {
+	return &CacheOptions{Enabled: false}
+}
+
+func NewMemoryCache(options MemoryCacheOptions) *MemoryCache {
+	c := &MemoryCache{
+		options: options,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		stop:    make(chan struct{}),
+	}
+	if options.SnapshotFile != "" {
+		f, err := os.Open(options.SnapshotFile)
+		if err == nil {
+			err = c.LoadFrom(f)
+			f.Close()
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.reportError(fmt.Errorf("loading cache snapshot: %w", err))
+		}
+	}
+	if options.CleanupInterval > 0 {
+		go c.cleanupLoop()
+	}
+	return c
+}
+
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if item.entry.expiredAt(clockNow(c.options.Clock)) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return item.entry, true
+}
+
+// Set stores entry as the most recently used one, evicting others as
+// needed. An entry over MaxEntryBytes, or over MaxBytes on its own, is not
+// stored and replaces nothing.
+func (c *MemoryCache) Set(key string, entry *CacheEntry) {
+	size := entrySize(key, entry)
+	if (c.options.MaxEntryBytes > 0 && size > c.options.MaxEntryBytes) ||
+		(c.options.MaxBytes > 0 && size > c.options.MaxBytes) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.entries[key]; exists {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&memoryCacheItem{key: key, entry: entry, size: size})
+	c.size += size
+	for (c.options.MaxEntries > 0 && len(c.entries) > c.options.MaxEntries) ||
+		(c.options.MaxBytes > 0 && c.size > c.options.MaxBytes) {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+func (c *MemoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.size = 0
+}
+
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// Size returns the approximate number of bytes held by the entries.
+func (c *MemoryCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// Range calls fn for every unexpired entry until fn returns false. fn may
+// modify the cache.
+func (c *MemoryCache) Range(fn func(key string, entry *CacheEntry) bool) {
+	items := c.items()
+	now := clockNow(c.options.Clock)
+	for _, item := range items {
+		if !item.entry.expiredAt(now) && !fn(item.key, item.entry) {
+			return
+		}
+	}
+}
+
+func (c *MemoryCache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		if c.options.SnapshotFile != "" {
+			var buf bytes.Buffer
+			err := c.SaveTo(&buf)
+			if err == nil {
+				err = writeFileAtomic(c.options.SnapshotFile, buf.Bytes())
+			}
+			if err != nil {
+				c.reportError(fmt.Errorf("saving cache snapshot: %w", err))
+			}
+		}
+	})
+}
+
+// SaveTo writes the unexpired entries as JSON, for LoadFrom to read back.
+func (c *MemoryCache) SaveTo(w io.Writer) error {
+	items := c.items()
+	entries := make(map[string]*CacheEntry, len(items))
+	now := clockNow(c.options.Clock)
+	for _, item := range items {
+		if !item.entry.expiredAt(now) {
+			entries[item.key] = item.entry
+		}
+	}
+	return json.NewEncoder(w).Encode(entries)
+}
+
+// LoadFrom adds the entries written by SaveTo, skipping any that have
+// expired since. Entries already in the cache with the same key are
+// replaced.
+func (c *MemoryCache) LoadFrom(r io.Reader) error {
+	var entries map[string]*CacheEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	now := clockNow(c.options.Clock)
+	for key, entry := range entries {
+		if entry != nil && !entry.expiredAt(now) {
+			c.Set(key, entry)
+		}
+	}
+	return nil
+}
+
+// items returns the entries from most to least recently used.
+func (c *MemoryCache) items() []*memoryCacheItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make([]*memoryCacheItem, 0, len(c.entries))
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		items = append(items, elem.Value.(*memoryCacheItem))
+	}
+	return items
+}
+
+func (c *MemoryCache) reportError(err error) {
+	if c.options.OnError != nil {
+		c.options.OnError(err)
+	}
+}
+
+func (c *MemoryCache) remove(elem *list.Element) {
+	item := c.lru.Remove(elem).(*memoryCacheItem)
+	delete(c.entries, item.key)
+	c.size -= item.size
+}
+
+// entrySize approximates the memory held by an entry: its body, key, URL,
+// header names and values, and a fixed overhead for the rest.
+func entrySize(key string, entry *CacheEntry) int64 {
+	size := int64(256 + len(key) + len(entry.Body) + len(entry.URL))
+	for name, values := range entry.Headers {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	for _, s := range entry.Vary {
+		size += int64(len(s))
+	}
+	for _, s := range entry.Tags {
+		size += int64(len(s))
+	}
+	return size
+}
+
+func (c *MemoryCache) cleanupLoop() {
+	ticker := time.NewTicker(c.options.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.removeExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *MemoryCache) removeExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := clockNow(c.options.Clock)
+	for _, elem := range c.entries {
+		if elem.Value.(*memoryCacheItem).entry.expiredAt(now) {
+			c.remove(elem)
+		}
+	}
+}
+
+// InvalidateCache removes the entries matched by matcher, such as
+// ByTag("user:42") after a write to that user, and returns how many were
+// removed. With a Namespace set, only the client's own entries are
+// considered. The cache must implement CacheRanger.
+func (c *Client) InvalidateCache(matcher CacheMatcher) (int, error) {
+	if c.CacheConfig == nil || c.CacheConfig.Cache == nil {
+		return 0, nil
+	}
+	ranger, ok := c.CacheConfig.Cache.(CacheRanger)
+	if !ok {
+		return 0, fmt.Errorf("%w: %T does not implement CacheRanger", ErrInvalidOption, c.CacheConfig.Cache)
+	}
+	inNamespace := ByNamespace(c.CacheConfig.Namespace)
+	var removed int
+	ranger.Range(func(key string, entry *CacheEntry) bool {
+		if c.CacheConfig.Namespace != "" && !inNamespace(key, entry) {
+			return true
+		}
+		if matcher(key, entry) {
+			c.CacheConfig.Cache.Delete(key)
+			removed++
+		}
+		return true
+	})
+	return removed, nil
+}
+
+// ClearCache removes the client's cached entries: the whole cache, or with
+// a Namespace set only that namespace, which needs a CacheRanger.
+func (c *Client) ClearCache() error {
+	if c.CacheConfig == nil || c.CacheConfig.Cache == nil {
+		return nil
+	}
+	if c.CacheConfig.Namespace == "" {
+		c.CacheConfig.Cache.Clear()
+		return nil
+	}
+	_, err := c.InvalidateCache(func(string, *CacheEntry) bool { return true })
+	return err
+}
+
+func (c *Client) cacheKey(options *RequestOptions, fullURL string) (string, bool) {
+	if c.CacheConfig == nil || c.CacheConfig.Cache == nil {
+		return "", false
+	}
+	httpMode := c.CacheConfig.Mode == CacheModeHTTP
+	if options.Cache == nil && c.CacheConfig.Mode == CacheModeTTL {
+		return "", false
+	}
+	if options.Cache != nil && !options.Cache.Enabled {
+		return "", false
+	}
+	if strings.ToUpper(options.Method) != "GET" {
+		return "", false
+	}
+	if httpMode {
+		if _, noStore := requestCacheControl(options)["no-store"]; noStore {
+			return "", false
+		}
+	}
+	key := "GET:" + fullURL
+	if options.Cache != nil && options.Cache.Key != "" {
+		key = options.Cache.Key
+	}
+	if c.CacheConfig.Namespace != "" {
+		key = c.CacheConfig.Namespace + ":" + key
+	}
+	return key, true
+}
+
+func (c *Client) cacheTTL(options *RequestOptions) time.Duration {
+	if options.Cache != nil && options.Cache.TTL > 0 {
+		return options.Cache.TTL
+	}
+	if c.CacheConfig.DefaultTTL > 0 {
+		return c.CacheConfig.DefaultTTL
+	}
+	return defaultCacheTTL
+}
+
+// cacheLookup reports whether a cacheable request may be answered from the
+// cache; in CacheModeHTTP a request with Cache-Control: no-cache goes to the
+// origin and only refreshes the entry, as do background revalidations.
+// Dry runs never use the cache.
+func (c *Client) cacheLookup(options *RequestOptions) bool {
+	if options.revalidate || options.dryRun != nil {
+		return false
+	}
+	if c.CacheConfig.Mode != CacheModeHTTP {
+		return true
+	}
+	_, noCache := requestCacheControl(options)["no-cache"]
+	return !noCache
+}
+
+func (c *Client) storeCacheEntry(key, fullURL string, options *RequestOptions, reqHeader http.Header, resp *Response) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return
+	}
+	now := clockNow(c.Clock)
+	ttl := c.cacheTTL(options)
+	swr, sie := c.CacheConfig.StaleWhileRevalidate, c.CacheConfig.StaleIfError
+	if c.CacheConfig.Mode == CacheModeHTTP {
+		var ok bool
+		if ttl, ok = c.httpCacheTTL(options, resp.Headers); !ok {
+			return
+		}
+		directives := parseCacheControl(resp.Headers.Get("Cache-Control"))
+		if seconds, err := strconv.Atoi(directives["stale-while-revalidate"]); err == nil {
+			swr = time.Duration(seconds) * time.Second
+		}
+		if seconds, err := strconv.Atoi(directives["stale-if-error"]); err == nil {
+			sie = time.Duration(seconds) * time.Second
+		}
+	}
+	vary, ok := varyHeaders(resp.Headers)
+	if !ok {
+		return
+	}
+	ttl = jitterTTL(ttl, c.CacheConfig.TTLJitter)
+	entry := &CacheEntry{
+		StatusCode:      resp.StatusCode,
+		Headers:         resp.Headers.Clone(),
+		Body:            append([]byte(nil), resp.Body...),
+		CreatedAt:       now,
+		StaleAt:         now.Add(ttl),
+		RevalidateUntil: now.Add(ttl + swr),
+		ExpiresAt:       now.Add(ttl + max(swr, sie)),
+		Vary:            vary,
+		URL:             fullURL,
+	}
+	if options.Cache != nil {
+		entry.Tags = options.Cache.Tags
+	}
+	c.CacheConfig.Cache.Set(key, entry)
+	if len(vary) > 0 {
+		c.CacheConfig.Cache.Set(varyKey(key, vary, reqHeader), entry)
+	}
+	if logger := c.logger(); logger != nil {
+		c.logCache(logger, CacheEvent{Decision: CacheDecisionStore, Key: key, URL: fullURL, TTL: ttl}, options.LogLevel)
+	}
+	if c.CacheConfig.OnCacheStore != nil {
+		c.CacheConfig.OnCacheStore(key, fullURL, entry)
+	}
+}
+
+// jitterTTL returns ttl changed by a random amount within ±fraction of it.
+func jitterTTL(ttl time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || ttl <= 0 {
+		return ttl
+	}
+	fraction = min(fraction, 1)
+	return time.Duration(float64(ttl) * (1 + fraction*(2*rand.Float64()-1)))
+}
+
+// varyHeaders returns the canonical header names listed in Vary, and false
+// for "Vary: *", which matches no later request.
+func varyHeaders(header http.Header) ([]string, bool) {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+func varyKey(key string, vary []string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString(key)
+	b.WriteString("|vary")
+	for _, name := range vary {
+		fmt.Fprintf(&b, "|%s=%q", name, strings.Join(header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// httpCacheTTL returns how long a response may be served from the cache
+// under CacheModeHTTP, and false when it must not be stored.
+func (c *Client) httpCacheTTL(options *RequestOptions, header http.Header) (time.Duration, bool) {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[directive]; ok {
+			return 0, false
+		}
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return 0, false
+		}
+		age, _ := strconv.Atoi(header.Get("Age"))
+		ttl := time.Duration(seconds-age) * time.Second
+		return ttl, ttl > 0
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0, false
+		}
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			date = clockNow(c.Clock)
+		}
+		ttl := expiresAt.Sub(date)
+		return ttl, ttl > 0
+	}
+
+	if options.Cache != nil && options.Cache.TTL > 0 {
+		return options.Cache.TTL, true
+	}
+	if c.CacheConfig.DefaultTTL > 0 {
+		return c.CacheConfig.DefaultTTL, true
+	}
+	return 0, false
+}
+
+func requestCacheControl(options *RequestOptions) map[string]string {
+	if value := options.header().Get("Cache-Control"); value != "" {
+		return parseCacheControl(value)
+	}
+	return nil
+}
+
+// parseCacheControl splits a Cache-Control header into lowercase directives
+// and their (unquoted) values.
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+	}
+	return directives
+}
+
+// cachedEntry looks up key and reports whether the entry may be served:
+// it is fresh, or stale but within its revalidation window, in which case a
+// background refresh is started. A stale entry kept only for StaleIfError is
+// returned with false.
+func (c *Client) cachedEntry(key string, options *RequestOptions) (*CacheEntry, bool) {
+	entry, ok := c.CacheConfig.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	now := clockNow(c.Clock)
+	if entry.staleAt(now) {
+		if !entry.revalidatableAt(now) {
+			return entry, false
+		}
+		c.revalidate(key, options)
+	}
+	return entry, true
+}
+
+// revalidate refreshes a stale entry in the background, at most once at a
+// time per key. A failed refresh leaves the stale entry in place and is
+// logged, since there is no caller to return it to.
+func (c *Client) revalidate(key string, options *RequestOptions) {
+	if _, running := c.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	refresh := cloneOptions(options)
+	refresh.revalidate = true
+	refresh.stats = nil
+	go func() {
+		defer c.revalidating.Delete(key)
+		if _, err := c.Request(refresh); err != nil {
+			if logger := c.logger(); logger != nil {
+				logger.LogError(fmt.Errorf("background revalidation: %w", err), refresh.LogLevel)
+			}
+		}
+	}()
+}
+
+// cacheFlight is a cacheable request on its way to the origin, which
+// identical requests wait for instead of sending their own.
+type cacheFlight struct {
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// flightKey returns the key that concurrent cache misses for the same
+// request share: the cache key plus the request headers, which a Vary
+// response may depend on, and the limits that decide whether the request
+// fails. Requests that can't be answered from the cache, that validate
+// status or intercept responses themselves, or that carry credentials
+// added after the headers are known, aren't coalesced.
+func (c *Client) flightKey(options *RequestOptions) (string, bool) {
+	if options.revalidate || options.ownOutcome || c.addsCredentials(options) {
+		return "", false
+	}
+	probe := *options
+	if probe.Method == "" {
+		probe.Method = "GET"
+	}
+	fullURL, err := c.buildURL(&probe)
+	if err != nil {
+		return "", false
+	}
+	key, ok := c.cacheKey(&probe, fullURL)
+	if !ok || !c.cacheLookup(&probe) {
+		return "", false
+	}
+	header := options.header()
+	pairs := make(map[string]string, len(header))
+	for name, values := range header {
+		pairs[name] = strings.Join(values, "\x00")
+	}
+	return fmt.Sprintf("%s|%d|%d|%s", key, options.Timeout, options.MaxContentLength, sortedPairs(pairs)), true
+}
+
+// addsCredentials reports whether the request gets credentials that are not
+// in its headers yet: Auth, cookies, a signature, an XSRF token or a token
+// from the client. Two such requests can be sent for different users even
+// when their headers match.
+func (c *Client) addsCredentials(options *RequestOptions) bool {
+	return options.Auth != nil || len(options.Cookies) > 0 || options.Signer != nil ||
+		options.XSRFCookieName != "" || c.XSRFCookieName != "" || c.Signer != nil ||
+		c.TokenSource != nil || c.TokenRefresher != nil
+}
+
+// coalesce sends the request, unless an identical one is already in flight;
+// then it waits for that request and shares its response or error, so a
+// burst of misses on one key reaches the origin once.
+func (c *Client) coalesce(key string, options *RequestOptions) (*Response, error) {
+	flight := &cacheFlight{done: make(chan struct{})}
+	if running, loaded := c.flights.LoadOrStore(key, flight); loaded {
+		leader := running.(*cacheFlight)
+		<-leader.done
+		if leader.err != nil {
+			return nil, leader.err
+		}
+		shared := *leader.resp
+		shared.Headers = leader.resp.Headers.Clone()
+		shared.Body = append([]byte(nil), leader.resp.Body...)
+		shared.useNumber = options.UseJSONNumber
+		return &shared, nil
+	}
+	defer func() {
+		c.flights.Delete(key)
+		close(flight.done)
+	}()
+	flight.resp, flight.err = c.send(options)
+	return flight.resp, flight.err
+}
+
+func cachedResponse(key string, entry *CacheEntry, now time.Time) *Response {
+	return &Response{
+		StatusCode: entry.StatusCode,
+		Headers:    entry.Headers.Clone(),
+		Body:       append([]byte(nil), entry.Body...),
+		FromCache:  true,
+		CacheKey:   key,
+		CacheAge:   now.Sub(entry.CreatedAt),
+		Stale:      entry.staleAt(now),
+	}
+}
@@ -2,28 +2,185 @@ package axios4go
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
+
+	"golang.org/x/oauth2"
 )
 
+// Client sends requests with shared settings. It is safe for concurrent
+// use: every request works on its own copy of the options and of the
+// http.Client, with a transport built for its settings, so concurrent
+// requests never see each other's timeout, redirect policy, proxy or
+// headers, and the options passed to Request can be shared between
+// goroutines. Interceptors and hooks can be registered at any time, but
+// the exported fields must not be changed while requests are in flight.
 type Client struct {
-	BaseURL    string
-	HTTPClient *http.Client
-	Logger     Logger
+	BaseURL        string
+	HTTPClient     *http.Client
+	Logger         Logger
+	CacheConfig    *CacheConfig
+	DNSFallback    *DNSFallback
+	ValidateStatus func(int) bool
+	Diagnostics    bool
+	TokenRefresher *TokenRefresher
+
+	// Requests whose URL is absolute are sent to that URL instead of
+	// BaseURL, like axios. DisallowAbsoluteURLs makes them fail instead, so
+	// requests can't leave BaseURL.
+	DisallowAbsoluteURLs bool
+
+	// UserAgent replaces DefaultUserAgent for requests that don't set a
+	// User-Agent header, and DisableUserAgent sends none at all. A request
+	// can send none by setting the header to "".
+	UserAgent        string
+	DisableUserAgent bool
+
+	// Defaults are merged with the options of every request, and the
+	// request wins where both set a field. Params and Headers are merged
+	// key by key. Fields neither sets keep the built-in defaults, such as
+	// a Timeout of 1000 ms and a MaxContentLength of 2000 bytes.
+	Defaults *RequestOptions
+
+	// DialContext and Resolver customize how connections are opened (for
+	// example through a VPN dialer or a test fake) while keeping the
+	// default transport. A custom HTTPClient.Transport keeps its own dialer
+	// unless a proxy or TLS option makes axios4go derive a transport from it.
+	DialContext DialContextFunc
+	Resolver    Resolver
+
+	// StaticDNS maps host names to fixed IPs (like curl --resolve) and
+	// IPFamily (IPFamilyIPv4 or IPFamilyIPv6) sets which address family is
+	// dialed first. RequestOptions can extend or override both.
+	StaticDNS map[string]string
+	IPFamily  string
+
+	// ProxyFunc picks a proxy for every request, e.g. a ProxyPool's Proxy
+	// method. RequestOptions.Proxy and RequestOptions.ProxyFunc override it.
+	ProxyFunc ProxyFunc
+
+	// CookieJar stores cookies from responses and replays them on later
+	// requests, e.g. NewCookieJar(). It takes precedence over
+	// HTTPClient.Jar.
+	CookieJar http.CookieJar
+
+	// XSRFCookieName names a CSRF cookie whose value is copied into the
+	// XSRFHeaderName header (DefaultXSRFHeaderName if empty) on every request
+	// except GET, HEAD, OPTIONS and TRACE. RequestOptions can override both.
+	XSRFCookieName string
+	XSRFHeaderName string
+
+	// KeepHeadersOnRedirect lists credential headers that are still sent
+	// when a redirect leads to another origin (scheme, host and port).
+	// Otherwise Authorization, Proxy-Authorization, Cookie, API key and XSRF
+	// headers are removed from such redirects.
+	KeepHeadersOnRedirect []string
+
+	// OnRedirect is called before every redirect is followed with the URL
+	// being left, the redirect target and the response status. Returning an
+	// error stops the request with it; modifying to rewrites the target.
+	// RequestOptions.OnRedirect replaces it.
+	OnRedirect func(from, to *url.URL, status int) error
+
+	// UnixSocket sends all requests over a Unix domain socket, such as
+	// /var/run/docker.sock. A BaseURL of "unix:///var/run/docker.sock" does
+	// the same.
+	UnixSocket string
+
+	// DisableKeepAlives closes the connection after every request instead
+	// of returning it to the pool.
+	DisableKeepAlives bool
+
+	// Protocol selects the HTTP version for transports axios4go builds:
+	// ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C or ProtocolHTTP3. Empty uses
+	// the net/http default of HTTP/2 when the server offers it over TLS.
+	Protocol string
+
+	// LogSampling, when set, logs only a sample of the requests that succeed
+	// quickly, so Debug logging can stay on in production.
+	LogSampling *LogSampling
+
+	// WireDump, when set, captures the raw request and response of failed
+	// and slow requests for debugging.
+	WireDump *WireDump
+
+	// Metrics, when set, observes every completed request.
+	Metrics Metrics
+
+	// Clock, when set, replaces the system clock for cache freshness and
+	// expiry decisions. Give the cache the same Clock.
+	Clock Clock
+
+	// Adapter, when set, sends requests instead of net/http.
+	Adapter Adapter
+
+	// HAR, when set, records every request sent to the origin so the
+	// session can be written as a HAR file.
+	HAR *HARRecorder
+
+	// TokenSource supplies the Authorization header for requests that do
+	// not set one, e.g. from golang.org/x/oauth2/clientcredentials or
+	// golang.org/x/oauth2/google. Wrap it in oauth2.ReuseTokenSource to
+	// cache tokens until they expire.
+	TokenSource oauth2.TokenSource
+
+	// TLSClientCert and TLSClientKey are PEM file paths of the client
+	// certificate presented to servers that require mutual TLS.
+	// TLSCertificate supplies an already loaded certificate instead, and
+	// TLS configures root CAs, protocol versions and verification.
+	TLSClientCert  string
+	TLSClientKey   string
+	TLSCertificate *tls.Certificate
+	TLS            *TLSOptions
+
+	// Signer signs every request after its headers are finalized, unless
+	// RequestOptions.Signer overrides it.
+	Signer Signer
+
+	// OnInterceptorTiming, when set, is called after every interceptor runs
+	// with the time it took.
+	OnInterceptorTiming func(InterceptorTiming)
+
+	loggerMu        sync.RWMutex
+	interceptorsMu  sync.RWMutex
+	registry        interceptorRegistry
+	hooksMu         sync.RWMutex
+	hooks           lifecycleHooks
+	diagnosticsSeen sync.Map
+	transports      transportCache
+	http3Broken     sync.Map
+	revalidating    sync.Map
+	flights         sync.Map
+	connCounters    connCounters
 }
 
+// Response is the result of a request. Request is only set for dry runs
+// (RequestOptions.DryRun) and holds the request that would have been sent.
 type Response struct {
 	StatusCode int
 	Headers    http.Header
 	Body       []byte
+	FromCache  bool
+	CacheKey   string
+	CacheAge   time.Duration
+	Stale      bool
+	RequestID  string
+	Protocol   string
+	ConnReused bool
+	Request    *http.Request
+	useNumber  bool
 }
 
 type Promise struct {
@@ -36,46 +193,119 @@ type Promise struct {
 	mu       sync.Mutex
 }
 
+type OptionsInterceptors []func(*RequestOptions) error
 type RequestInterceptors []func(*http.Request) error
 type ResponseInterceptors []func(*http.Response) error
+type ResponseBodyInterceptors []func(*Response) (*Response, error)
+type ErrorInterceptors []func(*RequestOptions, error) (*Response, error)
 type InterceptorOptions struct {
-	RequestInterceptors  RequestInterceptors
-	ResponseInterceptors ResponseInterceptors
+	OptionsInterceptors      OptionsInterceptors
+	RequestInterceptors      RequestInterceptors
+	ResponseInterceptors     ResponseInterceptors
+	ResponseBodyInterceptors ResponseBodyInterceptors
+	ErrorInterceptors        ErrorInterceptors
 }
 
 type RequestOptions struct {
-	Method             string
-	URL                string
-	BaseURL            string
-	Params             map[string]string
-	Body               interface{}
-	Headers            map[string]string
-	Timeout            int
-	Auth               *Auth
-	ResponseType       string
-	ResponseEncoding   string
-	MaxRedirects       int
-	MaxContentLength   int64
-	MaxBodyLength      int64
-	Decompress         bool
-	ValidateStatus     func(int) bool
-	InterceptorOptions InterceptorOptions
-	Proxy              *Proxy
-	OnUploadProgress   func(bytesRead, totalBytes int64)
-	OnDownloadProgress func(bytesRead, totalBytes int64)
-	LogLevel           LogLevel
+	Method                string
+	URL                   string
+	BaseURL               string
+	Params                map[string]string
+	Body                  interface{}
+	Headers               map[string]string
+	HeaderValues          http.Header
+	Timeout               int
+	StallTimeout          int
+	DialTimeout           int
+	TLSHandshakeTimeout   int
+	ResponseHeaderTimeout int
+	ExpectContinueTimeout int
+	Auth                  *Auth
+	ResponseType          string
+	ResponseEncoding      string
+	MaxRedirects          int
+	MaxContentLength      int64
+	MaxBodyLength         int64
+	Decompress            bool
+	ValidateStatus        func(int) bool
+	InterceptorOptions    InterceptorOptions
+	Proxy                 *Proxy
+	ProxyFunc             ProxyFunc
+	OnUploadProgress      func(bytesRead, totalBytes int64)
+	OnDownloadProgress    func(bytesRead, totalBytes int64)
+	LogLevel              LogLevel
+	LogOptions            *RequestLogOptions
+	Cache                 *CacheOptions
+	UseJSONNumber         bool
+	BodyTemplate          *template.Template
+	BodyTemplateData      interface{}
+	Signer                Signer
+	TLSClientCert         string
+	TLSClientKey          string
+	TLSCertificate        *tls.Certificate
+	TLS                   *TLSOptions
+	UnixSocket            string
+	CloseConnection       bool
+	Host                  string
+	ServerName            string
+	StaticDNS             map[string]string
+	IPFamily              string
+	Cookies               []*http.Cookie
+	XSRFCookieName        string
+	XSRFHeaderName        string
+	DisableRedirects      bool
+	KeepMethodOnRedirect  bool
+	CheckRedirect         func(req *http.Request, via []*http.Request) error
+	OnRedirect            func(from, to *url.URL, status int) error
+	DryRun                bool
+	HTTPClient            *http.Client
+	Transport             http.RoundTripper
+
+	revalidate bool
+	dryRun     *dryRun
+	stats      *requestStats
+	// skipBaseURL marks URL as complete, for requests from Transport.
+	skipBaseURL bool
+	// ctx is the caller's context, for requests from Transport, which is
+	// then the only timeout unless Timeout or the client's Defaults set one.
+	ctx context.Context
+	// ownOutcome marks requests with their own ValidateStatus or
+	// interceptors, whose outcome identical requests can't share.
+	ownOutcome bool
 }
 
+// Proxy configures the proxy of a request. Auth is sent as
+// Proxy-Authorization for both http:// and https:// targets. Headers are sent
+// on the CONNECT request that opens the tunnel for https:// targets, for
+// proxies that expect their own tokens there.
 type Proxy struct {
 	Protocol string
 	Host     string
 	Port     int
 	Auth     *Auth
+	Headers  map[string]string
 }
 
+// Auth holds request credentials. TokenFunc takes precedence over
+// BearerToken, which takes precedence over Basic credentials. APIKey is
+// sent in addition to any of them, as a header or query parameter
+// depending on In ("header" by default).
+//
+// Scheme selects Windows-integrated authentication instead: "ntlm" performs
+// the NTLM handshake with Username, Password and Domain, and "negotiate"
+// sends a Kerberos token from SPNEGO (or falls back to NTLM inside
+// Negotiate when SPNEGO is nil).
 type Auth struct {
-	Username string
-	Password string
+	Username    string
+	Password    string
+	BearerToken string
+	TokenFunc   func() (string, error)
+	APIKey      string
+	In          string
+	Name        string
+	Scheme      string
+	Domain      string
+	SPNEGO      SPNEGOProvider
 }
 
 type ProgressReader struct {
@@ -112,10 +342,34 @@ func (pw *ProgressWriter) Write(p []byte) (int, error) {
 
 var defaultClient = &Client{HTTPClient: &http.Client{}, Logger: NewLogger(LevelNone)}
 
+// Defaults are merged with the options of every request sent with the
+// package-level functions, such as Get and PostAsync, like axios.defaults
+// in JavaScript. The request wins where both set a field, and Params and
+// Headers are merged key by key. Set them before sending requests; they
+// must not be changed while requests are in flight.
+//
+//	axios4go.Defaults.Headers = map[string]string{"Accept": "application/json"}
+//	axios4go.Defaults.Timeout = 5000
+//	axios4go.Defaults.ValidateStatus = axios4go.DefaultValidateStatus
+var Defaults = &RequestOptions{}
+
+func DefaultValidateStatus(status int) bool {
+	return status >= 200 && status < 300
+}
+
 func (r *Response) JSON(v interface{}) error {
+	if r.useNumber {
+		return r.JSONNumber(v)
+	}
 	return json.Unmarshal(r.Body, v)
 }
 
+func (r *Response) JSONNumber(v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(r.Body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
 func (p *Promise) Then(fn func(*Response)) *Promise {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -299,18 +553,7 @@ func PatchAsync(urlStr string, body interface{}, options ...*RequestOptions) *Pr
 }
 
 func Request(method, urlStr string, options ...*RequestOptions) (*Response, error) {
-	reqOptions := &RequestOptions{
-		Method:           "GET",
-		URL:              urlStr,
-		Timeout:          1000,
-		ResponseType:     "json",
-		ResponseEncoding: "utf8",
-		MaxContentLength: 2000,
-		MaxBodyLength:    2000,
-		MaxRedirects:     21,
-		Decompress:       true,
-		ValidateStatus:   nil,
-	}
+	reqOptions := &RequestOptions{URL: urlStr}
 
 	if len(options) > 0 && options[0] != nil {
 		mergeOptions(reqOptions, options[0])
@@ -320,16 +563,74 @@ func Request(method, urlStr string, options ...*RequestOptions) (*Response, erro
 		reqOptions.Method = method
 	}
 
-	return defaultClient.Request(reqOptions)
+	return defaultClient.Request(applyDefaults(Defaults, reqOptions))
 }
 
 func RequestAsync(method, urlStr string, options ...*RequestOptions) *Promise {
-	resp, err := Request(method, urlStr, options...)
-	return &Promise{response: resp, err: err}
+	promise := NewPromise()
+	go func() {
+		resp, err := Request(method, urlStr, options...)
+		promise.resolve(resp, err)
+	}()
+	return promise
 }
 
 func (c *Client) Request(options *RequestOptions) (*Response, error) {
-	if options.Timeout == 0 {
+	ownOutcome := options.decidesOutcome()
+	options = c.withDefaults(options)
+	options.ownOutcome = options.ownOutcome || ownOutcome
+	if options.DryRun {
+		req, err := c.BuildRequest(options)
+		if err != nil {
+			return nil, err
+		}
+		return &Response{Headers: http.Header{}, Request: req}, nil
+	}
+	if c.Metrics != nil && options.stats == nil {
+		return c.observe(options)
+	}
+	if key, ok := c.flightKey(options); ok {
+		return c.coalesce(key, options)
+	}
+	return c.send(options)
+}
+
+func (c *Client) send(options *RequestOptions) (*Response, error) {
+	startTime := time.Now()
+	var token string
+	if c.TokenRefresher != nil {
+		token = c.TokenRefresher.freshToken()
+	}
+	resp, err := c.request(options)
+	if c.TokenRefresher != nil {
+		resp, err = c.TokenRefresher.retryUnauthorized(c, options, token, resp, err)
+	}
+	if err != nil {
+		resp, err = c.interceptError(options, c.newRequestError(options, err, time.Since(startTime)))
+	}
+	if err != nil {
+		err = c.newRequestError(options, err, time.Since(startTime))
+		c.lifecycle().failed(err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) interceptError(options *RequestOptions, err error) (*Response, error) {
+	for _, interceptor := range c.interceptors(options).errors {
+		resp, interceptedErr := interceptor(options, err)
+		if interceptedErr == nil && resp != nil {
+			return resp, nil
+		}
+		if interceptedErr != nil {
+			err = interceptedErr
+		}
+	}
+	return nil, err
+}
+
+func (c *Client) request(options *RequestOptions) (*Response, error) {
+	if options.Timeout == 0 && options.ctx == nil {
 		options.Timeout = 1000
 	}
 	if options.MaxContentLength == 0 {
@@ -354,6 +655,16 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 		options.Decompress = true
 	}
 
+	interceptors := c.interceptors(options)
+	if len(interceptors.options) > 0 {
+		options = cloneOptions(options)
+		for _, interceptor := range interceptors.options {
+			if err := interceptor(options); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrRequestInterceptor, err)
+			}
+		}
+	}
+
 	validMethods := map[string]bool{
 		"GET":     true,
 		"POST":    true,
@@ -365,64 +676,88 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 	}
 	upperMethod := strings.ToUpper(options.Method)
 	if !validMethods[upperMethod] {
-		return nil, fmt.Errorf("invalid HTTP method: %q", options.Method)
+		return nil, fmt.Errorf("%w: %q", ErrInvalidMethod, options.Method)
 	}
 
+	c.diagnose(options)
+	logger := c.logger()
+
 	startTime := time.Now()
-	var fullURL string
-	if c.BaseURL != "" {
-		var err error
-		fullURL, err = url.JoinPath(c.BaseURL, options.URL)
-		if err != nil {
-			return nil, err
-		}
-	} else if options.BaseURL != "" {
-		var err error
-		fullURL, err = url.JoinPath(options.BaseURL, options.URL)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		fullURL = options.URL
+	fullURL, err := c.buildURL(options)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(options.Params) > 0 {
-		parsedURL, err := url.Parse(fullURL)
-		if err != nil {
-			return nil, err
+	// Entries stored with a Vary header can only be matched once the request
+	// headers are final, so those are looked up again below.
+	// A stale entry that can no longer be served on its own is kept as
+	// staleEntry, to answer in place of an error within StaleIfError.
+	var varyEntry, staleEntry *CacheEntry
+	cacheKey, cacheable := c.cacheKey(options, fullURL)
+	hooks := c.lifecycle()
+	fromCache := func(entry *CacheEntry) *Response {
+		c.CacheConfig.hit(cacheKey, fullURL, entry)
+		response := cachedResponse(cacheKey, entry, clockNow(c.Clock))
+		response.useNumber = options.UseJSONNumber
+		if logger != nil {
+			decision := CacheDecisionHit
+			if response.Stale {
+				decision = CacheDecisionStale
+			}
+			c.logCache(logger, CacheEvent{Decision: decision, Key: cacheKey, URL: fullURL, Age: response.CacheAge}, options.LogLevel)
 		}
-		q := parsedURL.Query()
-		for k, v := range options.Params {
-			q.Add(k, v)
+		hooks.servedFromCache(cacheKey, response)
+		return response
+	}
+	if cacheable && c.cacheLookup(options) {
+		entry, ok := c.cachedEntry(cacheKey, options)
+		switch {
+		case ok && len(entry.Vary) > 0:
+			varyEntry = entry
+		case ok:
+			return fromCache(entry), nil
+		case entry != nil && len(entry.Vary) == 0:
+			staleEntry = entry
 		}
-		parsedURL.RawQuery = q.Encode()
-		fullURL = parsedURL.String()
 	}
 
 	var bodyReader io.Reader
 	var bodyLength int64
 
-	if options.Body != nil {
-		switch v := options.Body.(type) {
+	body := options.Body
+	if options.BodyTemplate != nil {
+		rendered, err := renderBodyTemplate(options.BodyTemplate, options.BodyTemplateData)
+		if err != nil {
+			return nil, err
+		}
+		body = rendered
+	}
+
+	var bodyBytes []byte
+	if body != nil {
+		switch v := body.(type) {
 		case string:
+			bodyBytes = []byte(v)
 			bodyReader = strings.NewReader(v)
 			bodyLength = int64(len(v))
 		case []byte:
+			bodyBytes = v
 			bodyReader = bytes.NewReader(v)
 			bodyLength = int64(len(v))
 		default:
-			jsonBody, err := json.Marshal(options.Body)
+			jsonBody, err := json.Marshal(body)
 			if err != nil {
 				return nil, err
 			}
+			bodyBytes = jsonBody
 			bodyReader = bytes.NewBuffer(jsonBody)
 			bodyLength = int64(len(jsonBody))
 		}
 		if options.MaxBodyLength > 0 && bodyLength > int64(options.MaxBodyLength) {
-			return nil, errors.New("request body length exceeded maxBodyLength")
+			return nil, ErrMaxBodyLength
 		}
 
-		if options.Body != nil && options.OnUploadProgress != nil {
+		if options.OnUploadProgress != nil {
 			bodyReader = &ProgressReader{
 				reader:     bodyReader,
 				total:      bodyLength,
@@ -431,24 +766,40 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 		}
 	}
 
-	req, err := http.NewRequest(options.Method, fullURL, bodyReader)
+	// net/http enforces Timeout itself; adapters get it as a deadline.
+	parent := options.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if c.Adapter != nil && options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, time.Duration(options.Timeout)*time.Millisecond)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	defer cancel()
+	ctx, proxyChoice := trackProxyChoice(ctx, options.ProxyFunc)
+	ctx = withLogOptions(ctx, options.LogOptions)
+	ctx = withCredentialHeaders(ctx, c.credentialHeaders(options))
+
+	req, err := http.NewRequestWithContext(ctx, options.Method, fullURL, bodyReader)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, interceptor := range options.InterceptorOptions.RequestInterceptors {
-		err = interceptor(req)
-		if err != nil {
-			return nil, fmt.Errorf("request interceptor failed: %w", err)
-		}
+	req.Close = options.CloseConnection || c.DisableKeepAlives
+
+	if err := interceptors.runRequest(req); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRequestInterceptor, err)
 	}
 
 	if options.Headers == nil {
 		options.Headers = make(map[string]string)
 	}
 
-	if options.Body != nil {
-		if _, exists := options.Headers["Content-Type"]; !exists {
+	if body != nil {
+		if _, exists := options.header()["Content-Type"]; !exists {
 			options.Headers["Content-Type"] = "application/json"
 		}
 	}
@@ -456,58 +807,161 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 	for key, value := range options.Headers {
 		req.Header.Set(key, value)
 	}
+	for key, values := range options.HeaderValues {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	// An empty User-Agent also keeps net/http from sending its own.
+	if _, exists := req.Header["User-Agent"]; !exists {
+		req.Header.Set("User-Agent", c.userAgent())
+	}
+
+	// net/http ignores a Host entry in the header map, so move it to req.Host.
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	if options.Host != "" {
+		req.Host = options.Host
+	}
+
+	for _, cookie := range options.Cookies {
+		req.AddCookie(cookie)
+	}
+	c.applyXSRF(req, options)
 
 	if options.Auth != nil {
-		auth := options.Auth.Username + ":" + options.Auth.Password
-		basicAuth := base64.StdEncoding.EncodeToString([]byte(auth))
-		req.Header.Set("Authorization", "Basic "+basicAuth)
+		if err := options.Auth.apply(req); err != nil {
+			return nil, err
+		}
+		if name := options.Auth.queryParam(); name != "" {
+			req = req.WithContext(withMaskedParams(req.Context(), name))
+		}
 	}
 
-	if c.Logger != nil {
-		c.Logger.LogRequest(req, options.LogLevel)
+	if c.TokenSource != nil && req.Header.Get("Authorization") == "" {
+		if err := applyTokenSource(c.TokenSource, req); err != nil {
+			return nil, err
+		}
 	}
 
-	c.HTTPClient.Timeout = time.Duration(options.Timeout) * time.Millisecond
+	if c.TokenRefresher != nil && req.Header.Get("Authorization") == "" {
+		if token := c.TokenRefresher.Token(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
 
-	if options.MaxRedirects > 0 {
-		c.HTTPClient.CheckRedirect = func(_ *http.Request, via []*http.Request) error {
-			if len(via) >= options.MaxRedirects {
-				return fmt.Errorf("too many redirects (max: %d)", options.MaxRedirects)
-			}
-			return nil
+	signer := options.Signer
+	if signer == nil {
+		signer = c.Signer
+	}
+	if signer != nil {
+		if err := signer.Sign(req, bodyBytes); err != nil {
+			return nil, fmt.Errorf("signing request: %w", err)
 		}
 	}
 
-	if options.Proxy != nil {
-		proxyStr := fmt.Sprintf("%s://%s:%d", options.Proxy.Protocol, options.Proxy.Host, options.Proxy.Port)
-		proxyURL, err := url.Parse(proxyStr)
-		if err != nil {
-			return nil, err
+	if varyEntry != nil {
+		entry, ok := c.cachedEntry(varyKey(cacheKey, varyEntry.Vary, req.Header), options)
+		if ok {
+			return fromCache(entry), nil
 		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
+		staleEntry = entry
+	}
+	if options.dryRun != nil {
+		options.dryRun.req, options.dryRun.body = req, bodyBytes
+		return nil, errDryRun
+	}
+
+	if cacheable && !options.revalidate {
+		c.CacheConfig.miss(cacheKey, fullURL)
+		if logger != nil {
+			c.logCache(logger, CacheEvent{Decision: CacheDecisionMiss, Key: cacheKey, URL: fullURL}, options.LogLevel)
+		}
+	}
+
+	sampled := c.LogSampling.sample(req)
+	if logger != nil && sampled {
+		logger.LogRequest(req, options.LogLevel)
+	}
+
+	adapter := c.Adapter
+	if adapter == nil {
+		// Per-request settings go on a copy so that concurrent requests never
+		// see each other's timeout, redirect policy or transport.
+		base := c.HTTPClient
+		if options.HTTPClient != nil {
+			base = options.HTTPClient
 		}
-		if options.Proxy.Auth != nil {
-			auth := options.Proxy.Auth.Username + ":" + options.Proxy.Auth.Password
-			basicAuth := base64.StdEncoding.EncodeToString([]byte(auth))
-			transport.ProxyConnectHeader = http.Header{
-				"Proxy-Authorization": {"Basic " + basicAuth},
+		httpClient := *base
+		httpClient.Timeout = time.Duration(options.Timeout) * time.Millisecond
+		if c.CookieJar != nil {
+			httpClient.Jar = c.CookieJar
+		}
+
+		httpClient.CheckRedirect = c.checkRedirect(base, options)
+
+		if options.HTTPClient != nil || options.Transport != nil {
+			httpClient.Transport = ownTransport(options, httpClient.Transport)
+		} else {
+			transport, err := c.buildTransport(options)
+			if err != nil {
+				return nil, err
+			}
+			if transport != nil {
+				httpClient.Transport = transport
 			}
 		}
-		c.HTTPClient.Transport = transport
-		defer func() {
-			c.HTTPClient.Transport = nil
-		}()
+		adapter = httpClientAdapter{client: &httpClient}
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	var connReused atomic.Bool
+	traceCtx := httptrace.WithClientTrace(req.Context(), c.connTrace(&connReused))
+	stats := options.stats
+	if stats == nil && c.HAR != nil {
+		stats = &requestStats{}
+	}
+	if stats != nil {
+		traceCtx = httptrace.WithClientTrace(traceCtx, stats.attempt(len(bodyBytes)))
+	}
+	req = req.WithContext(traceCtx)
+
+	hooks.requestStarted(req)
+	resp, err := adapter.Do(req, options)
+	if err == nil && resp == nil {
+		err = errors.New("adapter returned neither a response nor an error")
+	}
+	proxyChoice.report(err)
 	if err != nil {
-		if c.Logger != nil {
-			c.Logger.LogError(err, options.LogLevel)
+		maskURLError(err, req)
+		err = wrapTransportError(err)
+		if c.HAR != nil {
+			start, timing := stats.last()
+			c.HAR.record(req, bodyBytes, nil, nil, err, start, timing, c.credentialHeaders(options))
 		}
-		return nil, err
+		if logger != nil {
+			if !sampled {
+				c.logLateRequest(logger, req, bodyBytes, options.LogLevel)
+			}
+			c.logError(logger, req, err, options.LogLevel)
+		}
+		if staleEntry != nil {
+			return fromCache(staleEntry), nil
+		}
+		if c.WireDump != nil {
+			var reqErr *RequestError
+			if errors.As(c.newRequestError(options, err, time.Since(startTime)), &reqErr) {
+				reqErr.WireDump = c.WireDump.capture(req, bodyBytes, nil, nil, c.credentialHeaders(options))
+				err = reqErr
+			}
+		}
+		return nil, interceptors.runResponse(nil, err)
 	}
 
+	if resp.Body == nil {
+		resp.Body = http.NoBody
+	}
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil {
 			if err != nil {
@@ -518,6 +972,13 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 		}
 	}()
 
+	var responseReader io.Reader = resp.Body
+	if options.StallTimeout > 0 {
+		stallReader := newStallReader(resp.Body, time.Duration(options.StallTimeout)*time.Millisecond, cancel)
+		defer stallReader.stop()
+		responseReader = stallReader
+	}
+
 	var responseBody []byte
 	if options.OnDownloadProgress != nil {
 		buf := &bytes.Buffer{}
@@ -526,13 +987,13 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 			total:      resp.ContentLength,
 			onProgress: options.OnDownloadProgress,
 		}
-		_, err = io.Copy(progressWriter, resp.Body)
+		_, err = io.Copy(progressWriter, responseReader)
 		if err != nil {
 			return nil, err
 		}
 		responseBody = buf.Bytes()
 	} else {
-		responseBody, err = io.ReadAll(resp.Body)
+		responseBody, err = io.ReadAll(responseReader)
 		if err != nil {
 			return nil, err
 		}
@@ -540,31 +1001,313 @@ func (c *Client) Request(options *RequestOptions) (*Response, error) {
 	}
 
 	duration := time.Since(startTime)
+	if c.HAR != nil {
+		start, timing := stats.last()
+		c.HAR.record(req, bodyBytes, resp, responseBody, nil, start, timing, c.credentialHeaders(options))
+	}
 
-	if c.Logger != nil {
-		c.Logger.LogResponse(resp, responseBody, duration, options.LogLevel)
+	if logger != nil && (sampled || c.LogSampling.keep(resp.StatusCode, duration)) {
+		if !sampled {
+			c.logLateRequest(logger, req, bodyBytes, options.LogLevel)
+		}
+		logger.LogResponse(resp, responseBody, duration, options.LogLevel)
 	}
 
 	if int64(len(responseBody)) > int64(options.MaxContentLength) {
-		return nil, errors.New("response content length exceeded maxContentLength")
+		return nil, ErrMaxContentLength
 	}
 
-	if options.ValidateStatus != nil && !(options.ValidateStatus(resp.StatusCode)) {
-		return nil, fmt.Errorf("Request failed with status code: %v", resp.StatusCode)
+	response := &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       responseBody,
+		RequestID:  req.Header.Get(RequestIDHeader),
+		Protocol:   resp.Proto,
+		ConnReused: connReused.Load(),
+		useNumber:  options.UseJSONNumber,
 	}
+	hooks.responded(req, response, duration)
 
-	for _, interceptor := range options.InterceptorOptions.ResponseInterceptors {
-		err = interceptor(resp)
+	if staleEntry != nil && resp.StatusCode >= 500 {
+		return fromCache(staleEntry), nil
+	}
+
+	validateStatus := options.ValidateStatus
+	if validateStatus == nil {
+		validateStatus = c.ValidateStatus
+	}
+	rejected := validateStatus != nil && !validateStatus(resp.StatusCode)
+
+	var wireDump string
+	if c.WireDump != nil && (rejected || c.WireDump.slow(duration)) {
+		wireDump = c.WireDump.capture(req, bodyBytes, resp, responseBody, c.credentialHeaders(options))
+	}
+	var statusErr error
+	if rejected {
+		httpErr := newHTTPError(req, response, c.credentialHeaders(options))
+		httpErr.WireDump = wireDump
+		statusErr = httpErr
+	}
+
+	if err := interceptors.runResponse(resp, statusErr); err != nil {
+		return nil, err
+	}
+
+	for _, interceptor := range interceptors.body {
+		replacement, err := interceptor(response)
 		if err != nil {
-			return nil, fmt.Errorf("response interceptor failed: %w", err)
+			return nil, fmt.Errorf("%w: %w", ErrResponseInterceptor, err)
+		}
+		if replacement != nil {
+			response = replacement
 		}
 	}
 
-	return &Response{
-		StatusCode: resp.StatusCode,
-		Headers:    resp.Header,
-		Body:       responseBody,
-	}, err
+	if cacheable {
+		response.CacheKey = cacheKey
+		c.storeCacheEntry(cacheKey, fullURL, options, req.Header, response)
+	}
+
+	return response, err
+}
+
+func (c *Client) baseURL(options *RequestOptions) string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return options.BaseURL
+}
+
+// dryRun receives the request that a request with RequestOptions.dryRun set
+// builds instead of sending it.
+type dryRun struct {
+	req  *http.Request
+	body []byte
+}
+
+var errDryRun = errors.New("dry run")
+
+// buildRequest runs options through everything request does before sending,
+// including defaults, interceptors, auth and signing, and returns the
+// resulting request and its body.
+func (c *Client) buildRequest(options *RequestOptions) (*http.Request, []byte, error) {
+	if options == nil {
+		options = &RequestOptions{}
+	}
+	opts := c.withDefaults(options)
+	result := &dryRun{}
+	opts.dryRun = result
+	if _, err := c.request(opts); !errors.Is(err, errDryRun) {
+		return nil, nil, err
+	}
+	return result.req, result.body, nil
+}
+
+// BuildRequest returns the request that options would send, with option
+// merging, params, headers, auth, interceptors and signing applied, without
+// sending it, so tests can assert exactly what would go on the wire. The
+// request can be sent with any http.Client.
+func (c *Client) BuildRequest(options *RequestOptions) (*http.Request, error) {
+	req, body, err := c.buildRequest(options)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(context.Background())
+	if body != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+		req.ContentLength = int64(len(body))
+	}
+	return req, nil
+}
+
+// BuildRequest returns the request that options would send with the default
+// client, without sending it.
+func BuildRequest(options *RequestOptions) (*http.Request, error) {
+	return defaultClient.BuildRequest(options)
+}
+
+func (c *Client) buildURL(options *RequestOptions) (string, error) {
+	fullURL := options.URL
+	if base := c.baseURL(options); base != "" && !options.skipBaseURL {
+		if strings.HasPrefix(base, unixScheme) {
+			base = unixSocketHost
+		}
+		var err error
+		fullURL, err = c.joinBaseURL(base, options.URL)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(options.Params) > 0 {
+		parsedURL, err := url.Parse(fullURL)
+		if err != nil {
+			return "", err
+		}
+		q := parsedURL.Query()
+		for k, v := range options.Params {
+			q.Add(k, v)
+		}
+		parsedURL.RawQuery = q.Encode()
+		fullURL = parsedURL.String()
+	}
+	return fullURL, nil
+}
+
+// joinBaseURL appends rawURL to base, unless rawURL is absolute: then it is
+// used as is (taking base's scheme if it starts with "//"), or rejected
+// when DisallowAbsoluteURLs is set.
+func (c *Client) joinBaseURL(base, rawURL string) (string, error) {
+	ref, err := url.Parse(rawURL)
+	if err != nil || ref.Host == "" {
+		return url.JoinPath(base, rawURL)
+	}
+	if c.DisallowAbsoluteURLs {
+		return "", fmt.Errorf("%w: absolute URL %s on a client with a BaseURL and DisallowAbsoluteURLs", ErrInvalidOption, redactURL(rawURL))
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+func cloneOptions(options *RequestOptions) *RequestOptions {
+	clone := *options
+	if options.Params != nil {
+		clone.Params = make(map[string]string, len(options.Params))
+		for k, v := range options.Params {
+			clone.Params[k] = v
+		}
+	}
+	if options.Headers != nil {
+		clone.Headers = make(map[string]string, len(options.Headers))
+		for k, v := range options.Headers {
+			clone.Headers[k] = v
+		}
+	}
+	clone.HeaderValues = options.HeaderValues.Clone()
+	if options.Cache != nil {
+		cacheOptions := *options.Cache
+		clone.Cache = &cacheOptions
+	}
+	return &clone
+}
+
+// withDefaults returns a copy of options merged onto c.Defaults.
+func (c *Client) withDefaults(options *RequestOptions) *RequestOptions {
+	return applyDefaults(c.Defaults, options)
+}
+
+// applyDefaults returns a copy of options merged onto defaults. Requests
+// work on the copy, so callers can share options between goroutines.
+func applyDefaults(defaults, options *RequestOptions) *RequestOptions {
+	if defaults == nil {
+		return cloneOptions(options)
+	}
+	merged := cloneOptions(defaults)
+	mergeOptions(merged, options)
+	merged.Params = mergeParams(defaults.Params, options.Params)
+	overridden := make(map[string]bool, len(options.Headers)+len(options.HeaderValues))
+	for k := range options.Headers {
+		overridden[http.CanonicalHeaderKey(k)] = true
+	}
+	for k := range options.HeaderValues {
+		overridden[http.CanonicalHeaderKey(k)] = true
+	}
+	merged.Headers = mergeParams(keptHeaders(defaults.Headers, overridden), options.Headers)
+	merged.HeaderValues = mergeHeaderValues(defaults.HeaderValues, options.HeaderValues, overridden)
+	merged.revalidate = options.revalidate
+	merged.dryRun = options.dryRun
+	merged.stats = options.stats
+	merged.skipBaseURL = options.skipBaseURL
+	merged.ctx = options.ctx
+	merged.ownOutcome = options.ownOutcome
+	return merged
+}
+
+// mergeParams returns a new map with the params of defaults and options,
+// with options winning for the same key.
+func mergeParams(defaults, options map[string]string) map[string]string {
+	if defaults == nil && options == nil {
+		return nil
+	}
+	merged := make(map[string]string, len(defaults)+len(options))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range options {
+		merged[k] = v
+	}
+	return merged
+}
+
+// keptHeaders returns the defaults headers that the request doesn't
+// override. Header names are compared case insensitively.
+func keptHeaders(defaults map[string]string, overridden map[string]bool) map[string]string {
+	if defaults == nil {
+		return nil
+	}
+	kept := make(map[string]string, len(defaults))
+	for k, v := range defaults {
+		if !overridden[http.CanonicalHeaderKey(k)] {
+			kept[k] = v
+		}
+	}
+	return kept
+}
+
+// mergeHeaderValues returns a new header with the values of defaults the
+// request doesn't override and those of options.
+func mergeHeaderValues(defaults, options http.Header, overridden map[string]bool) http.Header {
+	if defaults == nil && options == nil {
+		return nil
+	}
+	merged := make(http.Header, len(defaults)+len(options))
+	for k, v := range defaults {
+		if !overridden[http.CanonicalHeaderKey(k)] {
+			merged[k] = append([]string(nil), v...)
+		}
+	}
+	for k, v := range options {
+		merged[k] = append([]string(nil), v...)
+	}
+	return merged
+}
+
+// decidesOutcome reports whether the options set callbacks that decide
+// the outcome of a request beyond what is sent.
+func (o *RequestOptions) decidesOutcome() bool {
+	i := o.InterceptorOptions
+	return o.ValidateStatus != nil || len(i.OptionsInterceptors) > 0 || len(i.RequestInterceptors) > 0 ||
+		len(i.ResponseInterceptors) > 0 || len(i.ResponseBodyInterceptors) > 0 || len(i.ErrorInterceptors) > 0
+}
+
+// header returns Headers and HeaderValues combined.
+func (o *RequestOptions) header() http.Header {
+	header := make(http.Header, len(o.Headers)+len(o.HeaderValues))
+	for key, value := range o.Headers {
+		header.Set(key, value)
+	}
+	for key, values := range o.HeaderValues {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return header
+}
+
+// AddHeader adds a value to the header key, keeping any others, for
+// headers sent more than once.
+func (o *RequestOptions) AddHeader(key, value string) *RequestOptions {
+	if o.HeaderValues == nil {
+		o.HeaderValues = make(http.Header)
+	}
+	o.HeaderValues.Add(key, value)
+	return o
 }
 
 func mergeOptions(dst, src *RequestOptions) {
@@ -586,9 +1329,15 @@ func mergeOptions(dst, src *RequestOptions) {
 	if src.Headers != nil {
 		dst.Headers = src.Headers
 	}
+	if src.HeaderValues != nil {
+		dst.HeaderValues = src.HeaderValues
+	}
 	if src.Timeout != 0 {
 		dst.Timeout = src.Timeout
 	}
+	if src.StallTimeout != 0 {
+		dst.StallTimeout = src.StallTimeout
+	}
 	if src.Auth != nil {
 		dst.Auth = src.Auth
 	}
@@ -610,12 +1359,21 @@ func mergeOptions(dst, src *RequestOptions) {
 	if src.ValidateStatus != nil {
 		dst.ValidateStatus = src.ValidateStatus
 	}
+	if src.InterceptorOptions.OptionsInterceptors != nil {
+		dst.InterceptorOptions.OptionsInterceptors = src.InterceptorOptions.OptionsInterceptors
+	}
 	if src.InterceptorOptions.RequestInterceptors != nil {
 		dst.InterceptorOptions.RequestInterceptors = src.InterceptorOptions.RequestInterceptors
 	}
 	if src.InterceptorOptions.ResponseInterceptors != nil {
 		dst.InterceptorOptions.ResponseInterceptors = src.InterceptorOptions.ResponseInterceptors
 	}
+	if src.InterceptorOptions.ResponseBodyInterceptors != nil {
+		dst.InterceptorOptions.ResponseBodyInterceptors = src.InterceptorOptions.ResponseBodyInterceptors
+	}
+	if src.InterceptorOptions.ErrorInterceptors != nil {
+		dst.InterceptorOptions.ErrorInterceptors = src.InterceptorOptions.ErrorInterceptors
+	}
 	if src.OnUploadProgress != nil {
 		dst.OnUploadProgress = src.OnUploadProgress
 	}
@@ -625,6 +1383,102 @@ func mergeOptions(dst, src *RequestOptions) {
 	if src.Proxy != nil {
 		dst.Proxy = src.Proxy
 	}
+	if src.ProxyFunc != nil {
+		dst.ProxyFunc = src.ProxyFunc
+	}
+	if src.Cache != nil {
+		dst.Cache = src.Cache
+	}
+	if src.UseJSONNumber {
+		dst.UseJSONNumber = src.UseJSONNumber
+	}
+	if src.BodyTemplate != nil {
+		dst.BodyTemplate = src.BodyTemplate
+	}
+	if src.BodyTemplateData != nil {
+		dst.BodyTemplateData = src.BodyTemplateData
+	}
+	if src.Signer != nil {
+		dst.Signer = src.Signer
+	}
+	if src.TLSClientCert != "" {
+		dst.TLSClientCert = src.TLSClientCert
+	}
+	if src.TLSClientKey != "" {
+		dst.TLSClientKey = src.TLSClientKey
+	}
+	if src.TLSCertificate != nil {
+		dst.TLSCertificate = src.TLSCertificate
+	}
+	if src.TLS != nil {
+		dst.TLS = src.TLS
+	}
+	if src.UnixSocket != "" {
+		dst.UnixSocket = src.UnixSocket
+	}
+	if src.Host != "" {
+		dst.Host = src.Host
+	}
+	if src.ServerName != "" {
+		dst.ServerName = src.ServerName
+	}
+	if src.StaticDNS != nil {
+		dst.StaticDNS = src.StaticDNS
+	}
+	if src.IPFamily != "" {
+		dst.IPFamily = src.IPFamily
+	}
+	if src.Cookies != nil {
+		dst.Cookies = src.Cookies
+	}
+	if src.XSRFCookieName != "" {
+		dst.XSRFCookieName = src.XSRFCookieName
+	}
+	if src.XSRFHeaderName != "" {
+		dst.XSRFHeaderName = src.XSRFHeaderName
+	}
+	if src.DisableRedirects {
+		dst.DisableRedirects = src.DisableRedirects
+	}
+	if src.KeepMethodOnRedirect {
+		dst.KeepMethodOnRedirect = src.KeepMethodOnRedirect
+	}
+	if src.CheckRedirect != nil {
+		dst.CheckRedirect = src.CheckRedirect
+	}
+	if src.OnRedirect != nil {
+		dst.OnRedirect = src.OnRedirect
+	}
+	if src.CloseConnection {
+		dst.CloseConnection = src.CloseConnection
+	}
+	if src.DialTimeout != 0 {
+		dst.DialTimeout = src.DialTimeout
+	}
+	if src.TLSHandshakeTimeout != 0 {
+		dst.TLSHandshakeTimeout = src.TLSHandshakeTimeout
+	}
+	if src.ResponseHeaderTimeout != 0 {
+		dst.ResponseHeaderTimeout = src.ResponseHeaderTimeout
+	}
+	if src.ExpectContinueTimeout != 0 {
+		dst.ExpectContinueTimeout = src.ExpectContinueTimeout
+	}
+	if src.LogLevel != LevelNone {
+		dst.LogLevel = src.LogLevel
+	}
+	if src.LogOptions != nil {
+		dst.LogOptions = src.LogOptions
+	}
+	if src.DryRun {
+		dst.DryRun = src.DryRun
+	}
+	if src.HTTPClient != nil {
+		dst.HTTPClient = src.HTTPClient
+	}
+	if src.Transport != nil {
+		dst.Transport = src.Transport
+	}
 	dst.Decompress = src.Decompress
 }
 
@@ -632,6 +1486,44 @@ func SetBaseURL(baseURL string) {
 	defaultClient.BaseURL = baseURL
 }
 
+// SetLogLevel changes the level of the default client's logger, which the
+// package-level functions use. It can be called at any time, and does
+// nothing when the logger was removed with SetLogger(nil).
+func SetLogLevel(level LogLevel) {
+	if logger := defaultClient.logger(); logger != nil {
+		logger.SetLevel(level)
+	}
+}
+
+// SetLogger replaces the default client's logger. It can be called while
+// requests are in flight; a nil logger turns logging off.
+func SetLogger(logger Logger) {
+	defaultClient.loggerMu.Lock()
+	defer defaultClient.loggerMu.Unlock()
+	defaultClient.Logger = logger
+}
+
+// SetUserAgent replaces DefaultUserAgent for the package-level functions.
+func SetUserAgent(userAgent string) {
+	defaultClient.UserAgent = userAgent
+}
+
+// SetCacheConfig configures caching for the package-level functions, such
+// as Get and GetAsync. A nil config disables it.
+func SetCacheConfig(config *CacheConfig) {
+	defaultClient.CacheConfig = config
+}
+
+func (c *Client) userAgent() string {
+	switch {
+	case c.DisableUserAgent:
+		return ""
+	case c.UserAgent != "":
+		return c.UserAgent
+	}
+	return DefaultUserAgent
+}
+
 func NewClient(baseURL string) *Client {
 	return &Client{
 		BaseURL:    baseURL,
@@ -639,3 +1531,9 @@ func NewClient(baseURL string) *Client {
 		Logger:     NewLogger(LevelNone),
 	}
 }
+
+func NewClientWithCache(baseURL string, config *CacheConfig) *Client {
+	client := NewClient(baseURL)
+	client.CacheConfig = config
+	return client
+}
@@ -0,0 +1,84 @@
+package axios4go
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// NewCookieJar returns an in-memory cookie jar that uses the public suffix
+// list, so a site can't set cookies for a whole top-level domain.
+func NewCookieJar() http.CookieJar {
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	return jar
+}
+
+// NewClientWithCookieJar returns a client that stores cookies from
+// Set-Cookie responses and sends them back on later requests.
+func NewClientWithCookieJar(baseURL string) *Client {
+	client := NewClient(baseURL)
+	client.CookieJar = NewCookieJar()
+	return client
+}
+
+func (r *Response) Cookies() []*http.Cookie {
+	return (&http.Response{Header: r.Headers}).Cookies()
+}
+
+func (r *Response) Cookie(name string) (*http.Cookie, bool) {
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == name {
+			return cookie, true
+		}
+	}
+	return nil, false
+}
+
+func CookieExpired(cookie *http.Cookie) bool {
+	if cookie.MaxAge < 0 {
+		return true
+	}
+	return !cookie.Expires.IsZero() && cookie.Expires.Before(time.Now())
+}
+
+// DefaultXSRFHeaderName is the header the XSRF cookie is copied into when
+// only XSRFCookieName is set.
+const DefaultXSRFHeaderName = "X-XSRF-TOKEN"
+
+// applyXSRF copies the XSRF cookie into its header on requests that can
+// change state, like axios's xsrfCookieName and xsrfHeaderName. The cookie is
+// looked up in the jar first, then among the request's own cookies. A header
+// the request already sets is left alone.
+func (c *Client) applyXSRF(req *http.Request, options *RequestOptions) {
+	cookieName, headerName := options.XSRFCookieName, options.XSRFHeaderName
+	if cookieName == "" {
+		cookieName = c.XSRFCookieName
+	}
+	if headerName == "" {
+		headerName = c.XSRFHeaderName
+	}
+	if headerName == "" {
+		headerName = DefaultXSRFHeaderName
+	}
+	if cookieName == "" || req.Header.Get(headerName) != "" {
+		return
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return
+	}
+
+	if c.CookieJar != nil {
+		for _, cookie := range c.CookieJar.Cookies(req.URL) {
+			if cookie.Name == cookieName {
+				req.Header.Set(headerName, cookie.Value)
+				return
+			}
+		}
+	}
+	if cookie, err := req.Cookie(cookieName); err == nil {
+		req.Header.Set(headerName, cookie.Value)
+	}
+}
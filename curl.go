@@ -0,0 +1,82 @@
+package axios4go
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DumpCurl renders the request that options would send as a curl command,
+// for reproducing issues outside Go. The request is fully resolved, with
+// the base URL, params, headers, auth, interceptors and signing applied,
+// but not sent. Credential headers are masked.
+func (c *Client) DumpCurl(options *RequestOptions) (string, error) {
+	req, body, err := c.buildRequest(options)
+	if err != nil {
+		return "", err
+	}
+	masked := sensitiveHeaders
+	if options != nil {
+		masked = c.credentialHeaders(options)
+	}
+	return curlCommand(req, body, masked), nil
+}
+
+// DumpCurl renders the request that options would send with the default
+// client as a curl command, or returns "" if it can't be built.
+func DumpCurl(options *RequestOptions) string {
+	command, err := defaultClient.DumpCurl(options)
+	if err != nil {
+		return ""
+	}
+	return command
+}
+
+// curlCommand renders req as a curl command line with the values of the
+// masked headers replaced by [MASKED].
+func curlCommand(req *http.Request, body []byte, masked []string) string {
+	args := []string{"curl"}
+	switch {
+	case req.Method == http.MethodHead:
+		args = append(args, "--head")
+	case req.Method != http.MethodGet:
+		args = append(args, "-X", req.Method)
+	}
+	args = append(args, shellQuote(requestURL(req)))
+
+	if req.Host != "" && req.Host != req.URL.Host {
+		args = append(args, "-H", shellQuote("Host: "+req.Host))
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if isMasked(name, masked) {
+				value = "[MASKED]"
+			}
+			args = append(args, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		args = append(args, "--data-raw", shellQuote(string(body)))
+	}
+	return strings.Join(args, " ")
+}
+
+func isMasked(name string, masked []string) bool {
+	for _, m := range masked {
+		if strings.EqualFold(m, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,73 @@
+package axios4go
+
+import (
+	"errors"
+	"strings"
+)
+
+func (c *Client) diagnose(options *RequestOptions) {
+	if !c.Diagnostics {
+		return
+	}
+	logger := c.logger()
+	if logger == nil {
+		return
+	}
+	for _, message := range c.collectDiagnostics(options) {
+		if _, seen := c.diagnosticsSeen.LoadOrStore(message, struct{}{}); seen {
+			continue
+		}
+		if diagnostic, ok := logger.(DiagnosticLogger); ok {
+			diagnostic.LogDiagnostic(message, options.LogLevel)
+		} else {
+			logger.LogError(errors.New("diagnostic: "+message), options.LogLevel)
+		}
+	}
+}
+
+func (c *Client) collectDiagnostics(options *RequestOptions) []string {
+	var messages []string
+	method := strings.ToUpper(options.Method)
+
+	if options.ResponseType != "" && options.ResponseType != "json" {
+		messages = append(messages, "ResponseType "+options.ResponseType+" has no effect; the response body is always returned as raw bytes")
+	}
+	if options.ResponseEncoding != "" && options.ResponseEncoding != "utf8" {
+		messages = append(messages, "ResponseEncoding "+options.ResponseEncoding+" has no effect; the response body is not transcoded")
+	}
+	if (options.Body != nil || options.BodyTemplate != nil) && (method == "GET" || method == "HEAD") {
+		messages = append(messages, "Body is set on a "+method+" request; most servers ignore it")
+	}
+	if options.BodyTemplateData != nil && options.BodyTemplate == nil {
+		messages = append(messages, "BodyTemplateData has no effect without BodyTemplate")
+	}
+	if options.OnUploadProgress != nil && options.Body == nil && options.BodyTemplate == nil {
+		messages = append(messages, "OnUploadProgress has no effect on a request without a body")
+	}
+	if options.Proxy != nil {
+		switch strings.ToLower(options.Proxy.Protocol) {
+		case "http", "https", "socks5":
+		default:
+			messages = append(messages, "Proxy protocol "+options.Proxy.Protocol+" is not supported by the HTTP transport")
+		}
+	}
+	if (options.HTTPClient != nil || options.Transport != nil) &&
+		(options.hasProxy() || options.TLS != nil || options.hasTransportTimeouts() || options.hasAddressOverrides() || options.UnixSocket != "") {
+		messages = append(messages, "Proxy, TLS, DNS, socket and phase timeout options have no effect on a request with its own HTTPClient or Transport")
+	}
+	if options.HTTPClient == nil && options.Transport == nil && c.customTransport() &&
+		(options.hasProxy() || c.ProxyFunc != nil || c.usesTLS(options) || options.hasTransportTimeouts() || options.hasAddressOverrides() || c.unixSocket(options) != "") {
+		messages = append(messages, "Proxy, TLS, DNS, socket and phase timeout options have no effect on a client whose HTTPClient.Transport is not an *http.Transport")
+	}
+	if options.StallTimeout > 0 && options.StallTimeout >= options.Timeout {
+		messages = append(messages, "StallTimeout has no effect when it is not shorter than Timeout")
+	}
+	if options.Cache != nil && options.Cache.Enabled {
+		if c.CacheConfig == nil || c.CacheConfig.Cache == nil {
+			messages = append(messages, "Cache has no effect on a client without a CacheConfig")
+		} else if method != "GET" {
+			messages = append(messages, "Cache has no effect on "+method+" requests")
+		}
+	}
+	return messages
+}
@@ -0,0 +1,249 @@
+package axios4go
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const diskCacheExt = ".entry"
+
+// DiskCacheOptions configures a DiskCache. Entries are stored under Dir,
+// one file per entry in 256 buckets. When MaxBytes is set, the least
+// recently written entries are removed once the files exceed it. Clock,
+// when set, decides when entries expire.
+type DiskCacheOptions struct {
+	Dir      string
+	MaxBytes int64
+	Clock    Clock
+
+	// OnError, when set, is called when the directory can't be fully read
+	// while evicting, clearing or ranging over entries, which the Cache
+	// methods can't return.
+	OnError func(error)
+}
+
+// DiskCache is a Cache that keeps entries in files, so they survive between
+// runs of CLI tools and batch jobs. Writes go to a temporary file that is
+// renamed into place, so a crash never leaves a partial entry behind.
+type DiskCache struct {
+	options DiskCacheOptions
+	mu      sync.Mutex
+	size    int64
+}
+
+type diskCacheEntry struct {
+	Key   string
+	Entry *CacheEntry
+}
+
+func NewDiskCache(options DiskCacheOptions) (*DiskCache, error) {
+	if options.Dir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		options.Dir = filepath.Join(dir, "axios4go")
+	}
+	if err := os.MkdirAll(options.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	c := &DiskCache{options: options}
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		c.size += file.size
+	}
+	return c, nil
+}
+
+func (c *DiskCache) Get(key string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var stored diskCacheEntry
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Key != key || stored.Entry == nil {
+		return nil, false
+	}
+	if stored.Entry.expiredAt(clockNow(c.options.Clock)) {
+		c.Delete(key)
+		return nil, false
+	}
+	return stored.Entry, true
+}
+
+// Set stores entry. Like the other Cache methods it has no error result, so
+// entries that can't be written are dropped.
+func (c *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(diskCacheEntry{Key: key, Entry: entry})
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return
+	}
+	c.size += int64(len(data)) - previous
+	if c.options.MaxBytes > 0 && c.size > c.options.MaxBytes {
+		c.evict()
+	}
+}
+
+// Range calls fn for every readable, unexpired entry until fn returns
+// false. fn may modify the cache.
+func (c *DiskCache) Range(fn func(key string, entry *CacheEntry) bool) {
+	c.mu.Lock()
+	files, err := c.files()
+	c.mu.Unlock()
+	if err != nil {
+		c.reportError(err)
+	}
+
+	now := clockNow(c.options.Clock)
+	for _, file := range files {
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			continue
+		}
+		var stored diskCacheEntry
+		if json.Unmarshal(data, &stored) != nil || stored.Entry == nil || stored.Entry.expiredAt(now) {
+			continue
+		}
+		if !fn(stored.Key, stored.Entry) {
+			return
+		}
+	}
+}
+
+func (c *DiskCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(c.path(key))
+}
+
+func (c *DiskCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		c.reportError(err)
+	}
+	for _, file := range files {
+		c.remove(file.path)
+	}
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.options.Dir, name[:2], name+diskCacheExt)
+}
+
+func (c *DiskCache) remove(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if os.Remove(path) == nil {
+		c.size -= info.Size()
+	}
+}
+
+// evict removes the oldest files until the cache fits in MaxBytes again.
+func (c *DiskCache) evict() {
+	files, err := c.files()
+	if err != nil {
+		c.reportError(err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, file := range files {
+		if c.size <= c.options.MaxBytes {
+			return
+		}
+		c.remove(file.path)
+	}
+}
+
+type diskCacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the entry files. When part of the directory can't be read,
+// it returns the files found so far along with the error.
+func (c *DiskCache) files() ([]diskCacheFile, error) {
+	var files []diskCacheFile
+	err := filepath.WalkDir(c.options.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, diskCacheExt) {
+			var info fs.FileInfo
+			if info, err = d.Info(); err == nil {
+				files = append(files, diskCacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+			}
+		}
+		// Entries removed by another process while walking are not errors.
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return files, fmt.Errorf("reading disk cache: %w", err)
+	}
+	return files, nil
+}
+
+func (c *DiskCache) reportError(err error) {
+	if c.options.OnError != nil {
+		c.options.OnError(err)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to path, syncs it
+// and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
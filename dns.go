@@ -0,0 +1,271 @@
+package axios4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+type DNSFallback struct {
+	Resolver Resolver
+	Dialer   *net.Dialer
+
+	transportOnce sync.Once
+	roundTripper  *http.Transport
+	fallbacks     atomic.Int64
+	successes     atomic.Int64
+	failures      atomic.Int64
+}
+
+type DNSFallbackStats struct {
+	Fallbacks int64
+	Successes int64
+	Failures  int64
+}
+
+type DoHResolver struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+type dohAnswer struct {
+	Type int    `json:"type"`
+	Data string `json:"data"`
+}
+
+type dohResponse struct {
+	Status int         `json:"Status"`
+	Answer []dohAnswer `json:"Answer"`
+}
+
+const (
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+)
+
+func NewDNSFallback(resolver Resolver) *DNSFallback {
+	return &DNSFallback{Resolver: resolver}
+}
+
+func NewDoHResolver(endpoint string) *DoHResolver {
+	return &DoHResolver{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (f *DNSFallback) Stats() DNSFallbackStats {
+	return DNSFallbackStats{
+		Fallbacks: f.fallbacks.Load(),
+		Successes: f.successes.Load(),
+		Failures:  f.failures.Load(),
+	}
+}
+
+func (f *DNSFallback) dialer() *net.Dialer {
+	if f.Dialer != nil {
+		return f.Dialer
+	}
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+}
+
+func (f *DNSFallback) transport() *http.Transport {
+	f.transportOnce.Do(func() {
+		f.roundTripper = http.DefaultTransport.(*http.Transport).Clone()
+		f.roundTripper.DialContext = f.DialContext
+	})
+	return f.roundTripper
+}
+
+func (f *DNSFallback) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f.dial(ctx, f.dialer().DialContext, network, addr)
+}
+
+func (f *DNSFallback) dial(ctx context.Context, dial DialContextFunc, network, addr string) (net.Conn, error) {
+	conn, err := dial(ctx, network, addr)
+	var dnsErr *net.DNSError
+	if err == nil || f.Resolver == nil || !errors.As(err, &dnsErr) {
+		return conn, err
+	}
+
+	host, port, splitErr := net.SplitHostPort(addr)
+	if splitErr != nil {
+		return nil, err
+	}
+
+	f.fallbacks.Add(1)
+	addrs, lookupErr := f.Resolver.LookupIPAddr(ctx, host)
+	if lookupErr != nil || len(addrs) == 0 {
+		f.failures.Add(1)
+		if lookupErr != nil {
+			return nil, fmt.Errorf("%w; fallback resolver failed: %v", err, lookupErr)
+		}
+		return nil, err
+	}
+
+	for _, ip := range addrs {
+		conn, dialErr := dial(ctx, network, net.JoinHostPort(ip.String(), port))
+		if dialErr == nil {
+			f.successes.Add(1)
+			return conn, nil
+		}
+		err = dialErr
+	}
+	f.failures.Add(1)
+	return nil, err
+}
+
+func (r *DoHResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	var addrs []net.IPAddr
+	for _, qtype := range []int{dnsTypeA, dnsTypeAAAA} {
+		answers, err := r.query(ctx, host, qtype)
+		if err != nil {
+			return nil, err
+		}
+		for _, answer := range answers {
+			if answer.Type != qtype {
+				continue
+			}
+			if ip := net.ParseIP(answer.Data); ip != nil {
+				addrs = append(addrs, net.IPAddr{IP: ip})
+			}
+		}
+		if len(addrs) > 0 {
+			return addrs, nil
+		}
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r *DoHResolver) query(ctx context.Context, host string, qtype int) ([]dohAnswer, error) {
+	endpoint, err := url.Parse(r.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := endpoint.Query()
+	q.Set("name", host)
+	q.Set("type", fmt.Sprint(qtype))
+	endpoint.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH query failed with status code: %d", resp.StatusCode)
+	}
+
+	var result dohResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Status != 0 {
+		return nil, &net.DNSError{Err: fmt.Sprintf("DoH query returned rcode %d", result.Status), Name: host}
+	}
+	return result.Answer, nil
+}
+
+// resolvingDial resolves host names with resolver and dials the returned
+// addresses in order with dial. IP literals are dialed directly.
+func resolvingDial(resolver Resolver, dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		for _, ip := range addrs {
+			var conn net.Conn
+			conn, err = dial(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+const (
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+)
+
+// addressDial routes hosts listed in staticDNS to fixed IPs and, when family
+// is set, dials the addresses of that IP family first. Everything else, and
+// any host whose lookup fails, goes to dial unchanged.
+func addressDial(staticDNS map[string]string, family string, resolver Resolver, dial DialContextFunc) DialContextFunc {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		if ip, ok := staticDNS[host]; ok {
+			return dial(ctx, network, net.JoinHostPort(ip, port))
+		}
+		if family == "" || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			return dial(ctx, network, addr)
+		}
+		sort.SliceStable(addrs, func(i, j int) bool {
+			return isFamily(addrs[i].IP, family) && !isFamily(addrs[j].IP, family)
+		})
+		for _, ip := range addrs {
+			var conn net.Conn
+			conn, err = dial(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+func isFamily(ip net.IP, family string) bool {
+	if family == IPFamilyIPv4 {
+		return ip.To4() != nil
+	}
+	return ip.To4() == nil
+}
+
+func validIPFamily(family string) bool {
+	return family == "" || family == IPFamilyIPv4 || family == IPFamilyIPv6
+}
@@ -0,0 +1,399 @@
+package axios4go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type ErrorCode string
+
+const (
+	ErrCodeConnAborted      ErrorCode = "ECONNABORTED"
+	ErrCodeNetwork          ErrorCode = "ERR_NETWORK"
+	ErrCodeBadResponse      ErrorCode = "ERR_BAD_RESPONSE"
+	ErrCodeBadRequest       ErrorCode = "ERR_BAD_REQUEST"
+	ErrCodeCanceled         ErrorCode = "ERR_CANCELED"
+	ErrCodeTooManyRedirects ErrorCode = "ERR_FR_TOO_MANY_REDIRECTS"
+	ErrCodeBadOptionValue   ErrorCode = "ERR_BAD_OPTION_VALUE"
+	ErrCodeInvalidURL       ErrorCode = "ERR_INVALID_URL"
+)
+
+const (
+	maxErrorBodyLength    = 1024
+	maxErrorSnippetLength = 200
+)
+
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+var (
+	ErrTimeout             = errors.New("request timed out")
+	ErrStalledTransfer     = errors.New("transfer stalled")
+	ErrTooManyRedirects    = errors.New("too many redirects")
+	ErrMaxContentLength    = errors.New("response content length exceeded maxContentLength")
+	ErrMaxBodyLength       = errors.New("request body length exceeded maxBodyLength")
+	ErrInvalidMethod       = errors.New("invalid HTTP method")
+	ErrInvalidAuth         = errors.New("invalid auth configuration")
+	ErrInvalidTLSConfig    = errors.New("invalid TLS configuration")
+	ErrCertificatePin      = errors.New("certificate pin mismatch")
+	ErrUnsupportedProtocol = errors.New("unsupported protocol")
+	ErrInvalidOption       = errors.New("invalid option value")
+	ErrBadStatus           = errors.New("request failed with bad status")
+	ErrRequestInterceptor  = errors.New("request interceptor failed")
+	ErrResponseInterceptor = errors.New("response interceptor failed")
+)
+
+type HTTPError struct {
+	Code           ErrorCode
+	StatusCode     int
+	Headers        http.Header
+	Body           []byte
+	Method         string
+	URL            string
+	RequestHeaders http.Header
+	Elapsed        time.Duration
+	Attempts       int
+	Response       *Response
+	Problem        *ProblemDetails
+	WireDump       string
+}
+
+type ProblemDetails struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (e *HTTPError) Error() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Request failed with status code: %v", e.StatusCode)
+	if e.Method != "" {
+		fmt.Fprintf(&buf, " (%s %s", e.Method, e.URL)
+		if e.Elapsed > 0 {
+			fmt.Fprintf(&buf, " after %v", roundElapsed(e.Elapsed))
+		}
+		buf.WriteString(")")
+	}
+	if snippet := bodySnippet(e.Body); snippet != "" {
+		fmt.Fprintf(&buf, ": %s", snippet)
+	}
+	return buf.String()
+}
+
+type RequestError struct {
+	Code           ErrorCode
+	Method         string
+	URL            string
+	RequestHeaders http.Header
+	Elapsed        time.Duration
+	Attempts       int
+	Err            error
+	WireDump       string
+}
+
+func (e *RequestError) Error() string {
+	if e.Method == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s failed after %v: %v", e.Method, e.URL, roundElapsed(e.Elapsed), e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RequestError) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"message":     e.Error(),
+		"code":        string(e.Code),
+		"method":      e.Method,
+		"url":         e.URL,
+		"duration_ms": float64(e.Elapsed.Microseconds()) / 1000,
+		"attempts":    e.Attempts,
+	}
+	if len(e.RequestHeaders) > 0 {
+		m["request_headers"] = e.RequestHeaders
+	}
+	if e.Err != nil {
+		m["cause"] = e.Err.Error()
+	}
+	return m
+}
+
+func (e *RequestError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToMap())
+}
+
+func (e *HTTPError) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"message":     e.Error(),
+		"code":        string(e.Code),
+		"status":      e.StatusCode,
+		"method":      e.Method,
+		"url":         e.URL,
+		"duration_ms": float64(e.Elapsed.Microseconds()) / 1000,
+		"attempts":    e.Attempts,
+	}
+	if len(e.RequestHeaders) > 0 {
+		m["request_headers"] = e.RequestHeaders
+	}
+	if len(e.Body) > 0 {
+		m["body"] = bodySnippet(e.Body)
+	}
+	if e.Problem != nil {
+		m["problem"] = e.Problem
+	}
+	return m
+}
+
+func (e *HTTPError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToMap())
+}
+
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrBadStatus
+}
+
+// newHTTPError builds the error for a rejected status. The credentials
+// headers of req are masked.
+func newHTTPError(req *http.Request, resp *Response, credentials []string) *HTTPError {
+	body := resp.Body
+	if len(body) > maxErrorBodyLength {
+		body = body[:maxErrorBodyLength]
+	}
+	code := ErrCodeBadResponse
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		code = ErrCodeBadRequest
+	}
+	return &HTTPError{
+		Code:           code,
+		StatusCode:     resp.StatusCode,
+		Headers:        resp.Headers,
+		Body:           append([]byte(nil), body...),
+		Method:         req.Method,
+		URL:            requestURL(req),
+		RequestHeaders: maskHeaders(req.Header, credentials),
+		Response:       resp,
+		Problem:        parseProblemDetails(resp),
+	}
+}
+
+func parseProblemDetails(resp *Response) *ProblemDetails {
+	mediaType, _, err := mime.ParseMediaType(resp.Headers.Get("Content-Type"))
+	if err != nil || mediaType != "application/problem+json" {
+		return nil
+	}
+
+	var problem ProblemDetails
+	if err := json.Unmarshal(resp.Body, &problem); err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &fields); err != nil {
+		return nil
+	}
+	for _, known := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(fields, known)
+	}
+	if len(fields) > 0 {
+		problem.Extensions = fields
+	}
+	return &problem
+}
+
+func wrapTransportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+func (c *Client) newRequestError(options *RequestOptions, err error, elapsed time.Duration) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.Elapsed == 0 {
+			httpErr.Elapsed = elapsed
+		}
+		if httpErr.Attempts == 0 {
+			httpErr.Attempts = 1
+		}
+		return err
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return err
+	}
+
+	reqErr = &RequestError{
+		Code:     classifyError(err),
+		Method:   strings.ToUpper(options.Method),
+		URL:      options.URL,
+		Elapsed:  elapsed,
+		Attempts: 1,
+		Err:      err,
+	}
+	if fullURL, urlErr := c.buildURL(options); urlErr == nil {
+		reqErr.URL = redactURL(fullURL)
+	}
+	if headers := options.header(); len(headers) > 0 {
+		reqErr.RequestHeaders = maskHeaders(headers, c.credentialHeaders(options))
+	}
+	return reqErr
+}
+
+func ErrorCodeOf(err error) ErrorCode {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Code
+	}
+	return classifyError(err)
+}
+
+func classifyError(err error) ErrorCode {
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrStalledTransfer):
+		return ErrCodeConnAborted
+	case errors.Is(err, context.Canceled):
+		return ErrCodeCanceled
+	case errors.Is(err, ErrTimeout):
+		return ErrCodeConnAborted
+	case errors.Is(err, ErrTooManyRedirects):
+		return ErrCodeTooManyRedirects
+	case errors.Is(err, ErrInvalidMethod), errors.Is(err, ErrInvalidAuth), errors.Is(err, ErrInvalidTLSConfig),
+		errors.Is(err, ErrUnsupportedProtocol), errors.Is(err, ErrInvalidOption):
+		return ErrCodeBadOptionValue
+	case errors.Is(err, ErrMaxBodyLength):
+		return ErrCodeBadRequest
+	case errors.Is(err, ErrMaxContentLength):
+		return ErrCodeBadResponse
+	case errors.As(err, &urlErr) && urlErr.Op == "parse":
+		return ErrCodeInvalidURL
+	case errors.As(err, &netErr), errors.As(err, &urlErr):
+		return ErrCodeNetwork
+	}
+	return ""
+}
+
+func maskHeaders(headers http.Header, names []string) http.Header {
+	masked := headers.Clone()
+	for _, name := range names {
+		if masked.Get(name) != "" {
+			masked.Set(name, "[MASKED]")
+		}
+	}
+	return masked
+}
+
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Redacted()
+}
+
+type maskedParamsKey struct{}
+
+// withMaskedParams records query parameters that carry credentials, such as
+// an API key sent with In "query", so requestURL masks their values.
+func withMaskedParams(ctx context.Context, names ...string) context.Context {
+	return context.WithValue(ctx, maskedParamsKey{}, append(maskedParams(ctx), names...))
+}
+
+func maskedParams(ctx context.Context) []string {
+	names, _ := ctx.Value(maskedParamsKey{}).([]string)
+	return names
+}
+
+type credentialHeadersKey struct{}
+
+// withCredentialHeaders records the headers that carry credentials in a
+// request, such as an API key under a custom Auth.Name, for loggers to mask.
+func withCredentialHeaders(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, credentialHeadersKey{}, names)
+}
+
+// credentialHeaderNames returns the headers recorded by
+// withCredentialHeaders, or the well-known ones for other requests.
+func credentialHeaderNames(ctx context.Context) []string {
+	if names, ok := ctx.Value(credentialHeadersKey{}).([]string); ok {
+		return names
+	}
+	return sensitiveHeaders
+}
+
+// requestURL returns the URL of req for display, without a password and
+// with the values of credential query parameters masked.
+func requestURL(req *http.Request) string {
+	return maskParams(req.URL, maskedParams(req.Context())).Redacted()
+}
+
+// MaskedURL returns the URL of a request sent by a Client the way errors and
+// logs show it: the password is redacted and the values of query parameters
+// that carry credentials, such as an API key sent with Auth In "query", are
+// masked. Transports and adapters that store requests use it to keep keys
+// out of their files.
+func MaskedURL(req *http.Request) string {
+	return requestURL(req)
+}
+
+// maskParams returns a copy of u with the values of the named query
+// parameters replaced by [MASKED], leaving the rest of the query as is.
+func maskParams(u *url.URL, names []string) *url.URL {
+	masked := *u
+	if len(names) == 0 || u.RawQuery == "" {
+		return &masked
+	}
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && isMasked(name, names) {
+			pairs[i] = key + "=[MASKED]"
+		}
+	}
+	masked.RawQuery = strings.Join(pairs, "&")
+	return &masked
+}
+
+// maskURLError masks the credential query parameters of req in the URL
+// that net/http puts in its errors.
+func maskURLError(err error, req *http.Request) {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && len(maskedParams(req.Context())) > 0 {
+		urlErr.URL = requestURL(req)
+	}
+}
+
+func bodySnippet(body []byte) string {
+	snippet := strings.TrimSpace(string(body))
+	if len(snippet) > maxErrorSnippetLength {
+		snippet = snippet[:maxErrorSnippetLength] + "..."
+	}
+	return strings.Join(strings.Fields(snippet), " ")
+}
+
+func roundElapsed(elapsed time.Duration) time.Duration {
+	if elapsed < time.Millisecond {
+		return elapsed.Round(time.Microsecond)
+	}
+	return elapsed.Round(time.Millisecond)
+}
@@ -0,0 +1,160 @@
+// Package fasthttpadapter provides an axios4go.Adapter that sends requests
+// with fasthttp, for high-QPS workloads where net/http's allocations show
+// up in profiles:
+//
+//	client := axios4go.NewClient("https://api.example.com")
+//	client.Adapter = fasthttpadapter.New(&fasthttp.Client{MaxConnsPerHost: 1024})
+//
+// Options, interceptors, auth, caching, logging and status validation still
+// run in axios4go, but fasthttp is not net/http and the adapter has limits:
+//
+//   - Requests are sent over HTTP/1.1 only; there is no HTTP/2 or HTTP/3.
+//   - RequestOptions that configure net/http, such as Proxy, TLS, Transport,
+//     DNS, the connection pool and the phase timeouts, are ignored; configure
+//     the fasthttp.Client instead.
+//   - Timeout applies to each hop of a redirect chain rather than to the
+//     whole request, and cancelling the context doesn't abort a request that
+//     has already been sent.
+//   - Redirects are followed up to MaxRedirects unless DisableRedirects is
+//     set, but OnRedirect and redirect header policies are not applied.
+//   - No httptrace events fire, so Metrics and HAR recordings have no phase
+//     timings, and the response Protocol is always HTTP/1.1.
+//   - Request and response bodies are buffered in memory.
+//   - An empty User-Agent still gets fasthttp's own unless the
+//     fasthttp.Client sets NoDefaultUserAgentHeader.
+package fasthttpadapter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rezmoss/axios4go"
+	"github.com/valyala/fasthttp"
+)
+
+// Adapter sends requests with a fasthttp.Client. It is safe for concurrent
+// use.
+type Adapter struct {
+	client *fasthttp.Client
+}
+
+// New returns an Adapter that sends requests with client, or with a
+// fasthttp.Client with default settings if client is nil.
+func New(client *fasthttp.Client) *Adapter {
+	if client == nil {
+		client = &fasthttp.Client{}
+	}
+	return &Adapter{client: client}
+}
+
+func (a *Adapter) Do(req *http.Request, options *axios4go.RequestOptions) (*http.Response, error) {
+	ctx := req.Context()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	freq := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(freq)
+	fresp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(fresp)
+
+	// Like net/http, ask for gzip and decompress transparently unless the
+	// caller chose the encodings itself.
+	decompress := false
+	if err := a.buildRequest(freq, req); err != nil {
+		return nil, err
+	}
+	if len(freq.Header.Peek("Accept-Encoding")) == 0 && req.Method != http.MethodHead {
+		freq.Header.Set("Accept-Encoding", "gzip")
+		decompress = true
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		freq.SetTimeout(time.Until(deadline))
+	}
+
+	maxRedirects := 0
+	if options != nil && !options.DisableRedirects {
+		maxRedirects = options.MaxRedirects
+	}
+	var err error
+	if maxRedirects > 0 {
+		err = a.client.DoRedirects(freq, fresp, maxRedirects)
+	} else {
+		err = a.client.Do(freq, fresp)
+	}
+	if err != nil {
+		if errors.Is(err, fasthttp.ErrTooManyRedirects) {
+			return nil, fmt.Errorf("%w (max: %d)", axios4go.ErrTooManyRedirects, maxRedirects)
+		}
+		if errors.Is(err, fasthttp.ErrTimeout) && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return toResponse(req, fresp, decompress)
+}
+
+func (a *Adapter) buildRequest(freq *fasthttp.Request, req *http.Request) error {
+	freq.SetRequestURI(req.URL.String())
+	freq.Header.SetMethod(req.Method)
+	for key, values := range req.Header {
+		for _, value := range values {
+			freq.Header.Add(key, value)
+		}
+	}
+	if req.Host != "" {
+		freq.Header.SetHost(req.Host)
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	freq.SetBody(body)
+	return nil
+}
+
+// toResponse copies fresp into an http.Response, since fasthttp reuses its
+// buffers once fresp is released.
+func toResponse(req *http.Request, fresp *fasthttp.Response, decompress bool) (*http.Response, error) {
+	header := http.Header{}
+	fresp.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	var body []byte
+	uncompressed := decompress && header.Get("Content-Encoding") == "gzip"
+	if uncompressed {
+		var err error
+		if body, err = fresp.BodyGunzip(); err != nil {
+			return nil, fmt.Errorf("fasthttpadapter: decompressing response: %w", err)
+		}
+		header.Del("Content-Encoding")
+	} else {
+		body = append([]byte(nil), fresp.Body()...)
+	}
+	if req.Method != http.MethodHead {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(fresp.StatusCode()) + " " + http.StatusText(fresp.StatusCode()),
+		StatusCode:    fresp.StatusCode(),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Uncompressed:  uncompressed,
+		Request:       req,
+	}, nil
+}
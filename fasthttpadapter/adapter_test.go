@@ -0,0 +1,82 @@
+package fasthttpadapter
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rezmoss/axios4go"
+	"github.com/rezmoss/axios4go/fixtures"
+)
+
+func TestAdapter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Expected gzip to be accepted, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"compressed":true}`))
+		gz.Close()
+	})
+	mux.Handle("/redirect", fixtures.RedirectChain(2, fixtures.JSON(http.StatusOK, "done")))
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := axios4go.NewClient(server.URL)
+	client.Adapter = New(nil)
+
+	resp, err := client.Request(&axios4go.RequestOptions{
+		Method:  "POST",
+		URL:     "/echo",
+		Body:    map[string]string{"name": "ann"},
+		Headers: map[string]string{"X-Tenant": "a"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated || string(resp.Body) != `{"name":"ann"}` {
+		t.Errorf("Unexpected response %d %s", resp.StatusCode, resp.Body)
+	}
+	if resp.Headers.Get("X-Method") != "POST" || resp.Headers.Get("X-Tenant") != "a" || len(resp.Headers.Values("X-Multi")) != 2 {
+		t.Errorf("Unexpected headers %v", resp.Headers)
+	}
+
+	resp, err = client.Request(&axios4go.RequestOptions{URL: "/gzip"})
+	if err != nil || string(resp.Body) != `{"compressed":true}` || resp.Headers.Get("Content-Encoding") != "" {
+		t.Errorf("Expected a decompressed body, got %v, %v", resp, err)
+	}
+
+	resp, err = client.Request(&axios4go.RequestOptions{URL: "/redirect"})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the redirects to be followed, got %v, %v", resp, err)
+	}
+	if _, err := client.Request(&axios4go.RequestOptions{URL: "/redirect", MaxRedirects: 1}); !errors.Is(err, axios4go.ErrTooManyRedirects) {
+		t.Errorf("Expected ErrTooManyRedirects, got %v", err)
+	}
+	resp, err = client.Request(&axios4go.RequestOptions{URL: "/redirect", DisableRedirects: true, ValidateStatus: func(int) bool { return true }})
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected the first redirect to be returned, got %v, %v", resp, err)
+	}
+
+	if _, err := client.Request(&axios4go.RequestOptions{URL: "/slow", Timeout: 50}); err == nil {
+		t.Error("Expected the request to time out")
+	}
+}
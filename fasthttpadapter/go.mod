@@ -0,0 +1,25 @@
+module github.com/rezmoss/axios4go/fasthttpadapter
+
+go 1.22.5
+
+require (
+	github.com/rezmoss/axios4go v0.6.2 // x-release-please-version
+	github.com/valyala/fasthttp v1.59.0
+)
+
+require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
+	github.com/andybalholm/brotli v1.1.1 // indirect
+	github.com/klauspost/compress v1.17.11 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/oauth2 v0.25.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+// The replace only applies inside this repository. Releases bump the
+// require above to the version being released, which is tagged on the same
+// commit as fasthttpadapter/vX.Y.Z, so consumers get an axios4go with the
+// Adapter API.
+replace github.com/rezmoss/axios4go => ../
@@ -0,0 +1,130 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func JSON(status int, v interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	})
+}
+
+func Slow(delay time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			next.ServeHTTP(w, r)
+		case <-r.Context().Done():
+		}
+	})
+}
+
+func Flaky(failures int, status int, next http.Handler) http.Handler {
+	var mu sync.Mutex
+	var calls int
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		failing := calls <= failures
+		mu.Unlock()
+
+		if failing {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func RedirectChain(hops int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hop, _ := strconv.Atoi(r.URL.Query().Get("hop"))
+		if hop >= hops {
+			next.ServeHTTP(w, r)
+			return
+		}
+		target := *r.URL
+		q := target.Query()
+		q.Set("hop", strconv.Itoa(hop+1))
+		target.RawQuery = q.Encode()
+		http.Redirect(w, r, target.RequestURI(), http.StatusFound)
+	})
+}
+
+func BasicAuth(username, password string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+			return
+		}
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != username || pass != password {
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func BearerAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+			return
+		}
+		if auth != "Bearer "+token {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func RateLimited(limit int, window time.Duration, next http.Handler) http.Handler {
+	var mu sync.Mutex
+	var count int
+	var windowStart time.Time
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		now := time.Now()
+		if now.Sub(windowStart) >= window {
+			windowStart = now
+			count = 0
+		}
+		count++
+		allowed := count <= limit
+		retryAfter := window - now.Sub(windowStart)
+		mu.Unlock()
+
+		if !allowed {
+			seconds := int((retryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func NewServer() *httptest.Server {
+	ok := JSON(http.StatusOK, map[string]string{"message": "ok"})
+
+	mux := http.NewServeMux()
+	mux.Handle("/ok", ok)
+	mux.Handle("/slow", Slow(2*time.Second, ok))
+	mux.Handle("/flaky", Flaky(2, http.StatusServiceUnavailable, ok))
+	mux.Handle("/redirect", RedirectChain(3, ok))
+	mux.Handle("/basic-auth", BasicAuth("user", "pass", ok))
+	mux.Handle("/bearer-auth", BearerAuth("token", ok))
+	mux.Handle("/rate-limited", RateLimited(1, time.Second, ok))
+	return httptest.NewServer(mux)
+}
@@ -0,0 +1,95 @@
+package fixtures
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, handler http.Handler, setup func(*http.Request)) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	if setup != nil {
+		setup(req)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFlaky(t *testing.T) {
+	handler := Flaky(2, http.StatusServiceUnavailable, JSON(http.StatusOK, "ok"))
+
+	for i := 0; i < 2; i++ {
+		if rec := get(t, handler, nil); rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected 503 on call %d, got %d", i+1, rec.Code)
+		}
+	}
+	if rec := get(t, handler, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 after failures, got %d", rec.Code)
+	}
+}
+
+func TestRedirectChain(t *testing.T) {
+	server := httptest.NewServer(RedirectChain(2, JSON(http.StatusOK, "done")))
+	defer server.Close()
+
+	var hops int
+	client := &http.Client{CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+		hops = len(via)
+		return nil
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	if hops != 2 {
+		t.Errorf("Expected 2 redirects, got %d", hops)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	basic := BasicAuth("user", "pass", JSON(http.StatusOK, "ok"))
+	if rec := get(t, basic, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without credentials, got %d", rec.Code)
+	}
+	if rec := get(t, basic, func(r *http.Request) { r.SetBasicAuth("user", "pass") }); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with credentials, got %d", rec.Code)
+	}
+
+	bearer := BearerAuth("token", JSON(http.StatusOK, "ok"))
+	if rec := get(t, bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with wrong token, got %d", rec.Code)
+	}
+	if rec := get(t, bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with token, got %d", rec.Code)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	handler := RateLimited(1, time.Minute, JSON(http.StatusOK, "ok"))
+
+	if rec := get(t, handler, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Expected first request to pass, got %d", rec.Code)
+	}
+	rec := get(t, handler, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
+
+func TestSlow(t *testing.T) {
+	start := time.Now()
+	get(t, Slow(50*time.Millisecond, JSON(http.StatusOK, "ok")), nil)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected handler to take at least 50ms, took %v", elapsed)
+	}
+}
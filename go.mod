@@ -1,3 +1,15 @@
 module github.com/rezmoss/axios4go
 
 go 1.22.5
+
+require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358
+	golang.org/x/net v0.35.0
+	golang.org/x/oauth2 v0.25.0
+)
+
+require (
+	github.com/google/go-cmp v0.6.0 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
@@ -0,0 +1,286 @@
+package axios4go
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const defaultHARMaxBodyBytes = 1 << 20
+
+// HAR is an HTTP Archive 1.2 document, the format browser devtools import
+// and export. Only the fields axios4go records are included.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is one request and its response. Requests that failed without
+// a response have a Response with Status 0 and the error in Error.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue is a header, cookie or query parameter.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is a response body. Encoding is "base64" for bodies that
+// aren't valid UTF-8.
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are in milliseconds; -1 marks a phase that didn't happen, such
+// as DNS on a reused connection. Connect includes SSL.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// ReadHAR reads a HAR document, such as one exported from browser devtools
+// or a debugging proxy. Fields axios4go doesn't record are ignored.
+func ReadHAR(r io.Reader) (*HAR, error) {
+	var har HAR
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("reading HAR: %w", err)
+	}
+	return &har, nil
+}
+
+// ReadHARFile reads the named HAR file.
+func ReadHARFile(name string) (*HAR, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadHAR(file)
+}
+
+// HARRecorder records the requests a client sends, with their headers,
+// bodies and timings, so a session can be written as a HAR file and opened
+// in browser devtools or shared with an API vendor. Bodies are cut off
+// after MaxBodyBytes (1 MiB by default) and credential headers are masked.
+// Responses served from the cache are not recorded. A HARRecorder is safe
+// for concurrent use and can be shared between clients.
+type HARRecorder struct {
+	MaxBodyBytes int
+
+	mu      sync.Mutex
+	entries []HAREntry
+}
+
+// HAR returns the recorded entries as a HAR document.
+func (r *HARRecorder) HAR() *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "axios4go"},
+		Entries: append([]HAREntry{}, r.entries...),
+	}}
+}
+
+// WriteTo writes the recorded entries to w as HAR JSON.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// WriteFile writes the recorded entries to the named file as HAR JSON.
+func (r *HARRecorder) WriteFile(name string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Reset discards the recorded entries.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// record adds req, sent with body at start, and resp, read into respBody,
+// or the error it failed with if resp is nil.
+func (r *HARRecorder) record(req *http.Request, body []byte, resp *http.Response, respBody []byte, reqErr error, start time.Time, timing RequestTiming, masked []string) {
+	total := time.Since(start)
+	entry := HAREntry{
+		StartedDateTime: start,
+		Time:            harMillis(total),
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         requestURL(req),
+			HTTPVersion: req.Proto,
+			Cookies:     []HARNameValue{},
+			Headers:     harHeaders(maskHeaderValues(req.Header, masked)),
+			QueryString: harHeaders(http.Header(maskParams(req.URL, maskedParams(req.Context())).Query())),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Timings: harTimings(timing, total),
+	}
+	for _, cookie := range req.Cookies() {
+		entry.Request.Cookies = append(entry.Request.Cookies, HARNameValue{Name: cookie.Name, Value: "[MASKED]"})
+	}
+	if body != nil {
+		entry.Request.PostData = &HARPostData{MimeType: req.Header.Get("Content-Type"), Text: string(r.truncate(body))}
+	}
+
+	entry.Response = HARResponse{Cookies: []HARNameValue{}, Headers: []HARNameValue{}, HeadersSize: -1, BodySize: -1}
+	if resp == nil {
+		if reqErr != nil {
+			entry.Error = reqErr.Error()
+		}
+	} else {
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = http.StatusText(resp.StatusCode)
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = harHeaders(maskHeaderValues(resp.Header, masked))
+		entry.Response.RedirectURL = resp.Header.Get("Location")
+		entry.Response.BodySize = len(respBody)
+		for _, cookie := range resp.Cookies() {
+			entry.Response.Cookies = append(entry.Response.Cookies, HARNameValue{Name: cookie.Name, Value: "[MASKED]"})
+		}
+		entry.Response.Content = HARContent{Size: len(respBody), MimeType: resp.Header.Get("Content-Type")}
+		text := r.truncate(respBody)
+		if utf8.Valid(text) {
+			entry.Response.Content.Text = string(text)
+		} else {
+			entry.Response.Content.Text = base64.StdEncoding.EncodeToString(text)
+			entry.Response.Content.Encoding = "base64"
+		}
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+}
+
+func (r *HARRecorder) truncate(body []byte) []byte {
+	maxBytes := r.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultHARMaxBodyBytes
+	}
+	if len(body) > maxBytes {
+		return body[:maxBytes]
+	}
+	return body
+}
+
+// harHeaders lists header in name order, one entry per value.
+func harHeaders(header http.Header) []HARNameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := []HARNameValue{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			values = append(values, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return values
+}
+
+// harTimings splits total into HAR phases. Send isn't measured separately
+// and is part of Wait.
+func harTimings(timing RequestTiming, total time.Duration) HARTimings {
+	phase := func(d time.Duration) float64 {
+		if d <= 0 {
+			return -1
+		}
+		return harMillis(d)
+	}
+	timings := HARTimings{
+		Blocked: -1,
+		DNS:     phase(timing.DNS),
+		Connect: phase(timing.Connect + timing.TLSHandshake),
+		SSL:     phase(timing.TLSHandshake),
+	}
+	if timing.TimeToFirstByte > 0 {
+		timings.Wait = harMillis(max(timing.TimeToFirstByte-timing.DNS-timing.Connect-timing.TLSHandshake, 0))
+		timings.Receive = harMillis(max(total-timing.TimeToFirstByte, 0))
+	} else {
+		timings.Wait = harMillis(total)
+	}
+	return timings
+}
+
+func harMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,131 @@
+package axios4go
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryInfo describes a request that is about to be sent again. Attempt is
+// the number of the attempt being started, counting from 1, MaxAttempts is
+// the most the request will be sent and Delay is how long the client waits
+// before sending it. Reason says why the previous attempt is not final.
+type RetryInfo struct {
+	Method      string
+	URL         string
+	Attempt     int
+	MaxAttempts int
+	Delay       time.Duration
+	Reason      string
+}
+
+// lifecycleHooks holds the hooks registered with OnRequestStart,
+// OnResponse, OnError, OnRetry and OnCacheHit.
+type lifecycleHooks struct {
+	requestStart []func(*http.Request)
+	response     []func(*http.Request, *Response, time.Duration)
+	errors       []func(error)
+	retry        []func(RetryInfo)
+	cacheHit     []func(string, *Response)
+}
+
+// OnRequestStart registers a hook called every time a request is about to
+// be sent to the origin, with its final headers. It is not called for
+// requests answered from the cache.
+//
+// Lifecycle hooks observe requests for telemetry and auditing without
+// changing them, unlike interceptors. They run synchronously in the order
+// they were registered, so they should return quickly, and must not modify
+// what they are passed.
+func (c *Client) OnRequestStart(hook func(req *http.Request)) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+	c.hooks.requestStart = append(c.hooks.requestStart, hook)
+}
+
+// OnResponse registers a hook called for every response received from the
+// origin, including those ValidateStatus rejects, with the time since the
+// request started.
+func (c *Client) OnResponse(hook func(req *http.Request, resp *Response, duration time.Duration)) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+	c.hooks.response = append(c.hooks.response, hook)
+}
+
+// OnError registers a hook called with the error a request fails with, a
+// *RequestError or *HTTPError, after error interceptors have run.
+func (c *Client) OnError(hook func(err error)) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+	c.hooks.errors = append(c.hooks.errors, hook)
+}
+
+// OnRetry registers a hook called before a request is sent again, such as
+// after a TokenRefresher refreshed the token of a request that got a 401.
+func (c *Client) OnRetry(hook func(info RetryInfo)) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+	c.hooks.retry = append(c.hooks.retry, hook)
+}
+
+// OnCacheHit registers a hook called with the cache key and the response
+// when a request is answered from the cache, including stale entries served
+// in place of an error.
+func (c *Client) OnCacheHit(hook func(key string, resp *Response)) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+	c.hooks.cacheHit = append(c.hooks.cacheHit, hook)
+}
+
+// lifecycle returns the registered hooks. Hooks are only ever appended, so
+// the returned slices stay valid while more are registered.
+func (c *Client) lifecycle() lifecycleHooks {
+	c.hooksMu.RLock()
+	defer c.hooksMu.RUnlock()
+	return c.hooks
+}
+
+func (h lifecycleHooks) requestStarted(req *http.Request) {
+	for _, hook := range h.requestStart {
+		hook(req)
+	}
+}
+
+func (h lifecycleHooks) responded(req *http.Request, resp *Response, duration time.Duration) {
+	for _, hook := range h.response {
+		hook(req, resp, duration)
+	}
+}
+
+func (h lifecycleHooks) failed(err error) {
+	for _, hook := range h.errors {
+		hook(err)
+	}
+}
+
+func (h lifecycleHooks) retrying(info RetryInfo) {
+	for _, hook := range h.retry {
+		hook(info)
+	}
+}
+
+func (h lifecycleHooks) servedFromCache(key string, resp *Response) {
+	for _, hook := range h.cacheHit {
+		hook(key, resp)
+	}
+}
+
+// retryInfo describes the next attempt of the request made with options.
+func (c *Client) retryInfo(options *RequestOptions, attempt, maxAttempts int, reason string) RetryInfo {
+	info := RetryInfo{
+		Method:      strings.ToUpper(options.Method),
+		URL:         options.URL,
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+		Reason:      reason,
+	}
+	if fullURL, err := c.buildURL(options); err == nil {
+		info.URL = redactURL(fullURL)
+	}
+	return info
+}
@@ -0,0 +1,30 @@
+module github.com/rezmoss/axios4go/http3
+
+go 1.22.5
+
+require (
+	github.com/quic-go/quic-go v0.48.2
+	github.com/rezmoss/axios4go v0.6.2 // x-release-please-version
+)
+
+require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
+	github.com/go-task/slim-sprig v0.0.0-20230315185526-52ccab3ef572 // indirect
+	github.com/google/pprof v0.0.0-20210407192527-94a9f03dee38 // indirect
+	github.com/onsi/ginkgo/v2 v2.9.5 // indirect
+	github.com/quic-go/qpack v0.5.1 // indirect
+	go.uber.org/mock v0.4.0 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
+	golang.org/x/mod v0.17.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/oauth2 v0.25.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
+)
+
+// The replace only applies inside this repository. Releases bump the
+// require above to the version being released, which is tagged on the same
+// commit as http3/vX.Y.Z, so consumers get an axios4go with RegisterHTTP3.
+replace github.com/rezmoss/axios4go => ../
@@ -0,0 +1,45 @@
+// Package http3 adds HTTP/3 support to axios4go. It is a separate module so
+// that only programs that use HTTP/3 depend on quic-go. Import it for its
+// side effect, then select the protocol on a client:
+//
+//	import _ "github.com/rezmoss/axios4go/http3"
+//
+//	client.Protocol = axios4go.ProtocolHTTP3
+package http3
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/rezmoss/axios4go"
+)
+
+// handshakeTimeout bounds the QUIC handshake so the TCP fallback still fits
+// in a request's Timeout.
+const handshakeTimeout = 500 * time.Millisecond
+
+func init() {
+	axios4go.RegisterHTTP3(func(tlsConfig *tls.Config) http.RoundTripper {
+		return &http3.Transport{
+			TLSClientConfig: tlsConfig,
+			QUICConfig:      &quic.Config{HandshakeIdleTimeout: handshakeTimeout},
+			Dial:            dial,
+		}
+	})
+}
+
+// dial connects like http3.Transport does by default, and marks failures
+// with axios4go.ErrHTTP3Unavailable: no request has been sent yet, so any
+// request can fall back to TCP.
+func dial(ctx context.Context, addr string, tlsConfig *tls.Config, config *quic.Config) (quic.EarlyConnection, error) {
+	conn, err := quic.DialAddrEarly(ctx, addr, tlsConfig, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", axios4go.ErrHTTP3Unavailable, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,60 @@
+package http3
+
+import (
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/rezmoss/axios4go"
+)
+
+func TestHTTP3(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP unavailable: %v", err)
+	}
+	h3Server := &http3.Server{Handler: handler, TLSConfig: http3.ConfigureTLSConfig(tlsServer.TLS.Clone())}
+	go h3Server.Serve(conn)
+	defer h3Server.Close()
+
+	t.Run("HTTP3", func(t *testing.T) {
+		client := axios4go.NewClient("https://" + conn.LocalAddr().String())
+		client.Protocol = axios4go.ProtocolHTTP3
+		client.TLS = &axios4go.TLSOptions{RootCAs: rootPEM}
+
+		resp, err := client.Request(&axios4go.RequestOptions{Method: "GET"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.Protocol != "HTTP/3.0" {
+			t.Errorf("Expected HTTP/3.0, got %q", resp.Protocol)
+		}
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		client := axios4go.NewClient(tlsServer.URL)
+		client.Protocol = axios4go.ProtocolHTTP3
+		client.TLS = &axios4go.TLSOptions{RootCAs: rootPEM}
+
+		resp, err := client.Request(&axios4go.RequestOptions{Method: "POST", Body: "payload"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.Protocol != "HTTP/2.0" {
+			t.Errorf("Expected fallback to HTTP/2.0, got %q", resp.Protocol)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package axios4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type InterceptorConfig struct {
+	Name     string
+	Priority int
+	RunWhen  func(*RequestOptions) bool
+}
+
+// InterceptorTiming reports how long a single interceptor took to run. Phase
+// is one of "options", "request", "response", "body" or "error". Interceptors
+// registered without a Name are reported as "<phase>#<position>".
+type InterceptorTiming struct {
+	Name     string
+	Phase    string
+	Duration time.Duration
+	Err      error
+}
+
+type requestInterceptor struct {
+	onFulfilled func(*http.Request) error
+	onRejected  func(*http.Request, error) error
+}
+
+type responseInterceptor struct {
+	onFulfilled func(*http.Response) error
+	onRejected  func(*http.Response, error) error
+}
+
+type interceptorEntry[T any] struct {
+	handler  T
+	name     string
+	priority int
+	order    int
+	runWhen  func(*RequestOptions) bool
+}
+
+type interceptorList[T any] []interceptorEntry[T]
+
+type interceptorRegistry struct {
+	sequence             int
+	optionsInterceptors  interceptorList[func(*RequestOptions) error]
+	requestInterceptors  interceptorList[requestInterceptor]
+	responseInterceptors interceptorList[responseInterceptor]
+	bodyInterceptors     interceptorList[func(*Response) (*Response, error)]
+	errorInterceptors    interceptorList[func(*RequestOptions, error) (*Response, error)]
+}
+
+type interceptorChain struct {
+	options  []func(*RequestOptions) error
+	request  []requestInterceptor
+	response []responseInterceptor
+	body     []func(*Response) (*Response, error)
+	errors   []func(*RequestOptions, error) (*Response, error)
+}
+
+func (l *interceptorList[T]) add(handler T, config []*InterceptorConfig, order int) {
+	entry := interceptorEntry[T]{handler: handler, order: order}
+	if len(config) > 0 && config[0] != nil {
+		entry.name = config[0].Name
+		entry.priority = config[0].Priority
+		entry.runWhen = config[0].RunWhen
+	}
+	*l = append(*l, entry)
+}
+
+func (l interceptorList[T]) merge(options *RequestOptions, perRequest []T, order int, wrap func(T, string) T) []T {
+	if len(l) == 0 && wrap == nil {
+		return perRequest
+	}
+	entries := make(interceptorList[T], 0, len(l)+len(perRequest))
+	for _, entry := range l {
+		if entry.runWhen == nil || entry.runWhen(options) {
+			entries = append(entries, entry)
+		}
+	}
+	for i, handler := range perRequest {
+		entries = append(entries, interceptorEntry[T]{handler: handler, order: order + i})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority > entries[j].priority
+		}
+		return entries[i].order < entries[j].order
+	})
+
+	handlers := make([]T, len(entries))
+	for i, entry := range entries {
+		handlers[i] = entry.handler
+		if wrap != nil {
+			handlers[i] = wrap(entry.handler, entry.name)
+		}
+	}
+	return handlers
+}
+
+func (ch interceptorChain) runRequest(req *http.Request) error {
+	var err error
+	for _, interceptor := range ch.request {
+		if err == nil {
+			if interceptor.onFulfilled != nil {
+				err = interceptor.onFulfilled(req)
+			}
+		} else if interceptor.onRejected != nil {
+			err = interceptor.onRejected(req, err)
+		}
+	}
+	return err
+}
+
+// runResponse runs the response interceptors. cause is the error the
+// request already failed with, from the transport or ValidateStatus, and
+// starts the chain off rejected. A transport error has no response to
+// recover to, so resp is nil, the onFulfilled handlers are skipped and cause
+// is kept if a handler returns nil.
+func (ch interceptorChain) runResponse(resp *http.Response, cause error) error {
+	err := cause
+	for _, interceptor := range ch.response {
+		if err == nil {
+			if interceptor.onFulfilled != nil && resp != nil {
+				err = interceptor.onFulfilled(resp)
+			}
+		} else if interceptor.onRejected != nil {
+			err = interceptor.onRejected(resp, err)
+		}
+	}
+	if err == nil && resp == nil {
+		return cause
+	}
+	if err != nil && (cause == nil || !errors.Is(err, cause)) {
+		return fmt.Errorf("%w: %w", ErrResponseInterceptor, err)
+	}
+	return err
+}
+
+func (c *Client) AddOptionsInterceptor(interceptor func(*RequestOptions) error, config ...*InterceptorConfig) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+
+	c.registry.sequence++
+	c.registry.optionsInterceptors.add(interceptor, config, c.registry.sequence)
+}
+
+func (c *Client) AddRequestInterceptor(interceptor func(*http.Request) error, config ...*InterceptorConfig) {
+	c.UseRequestInterceptor(interceptor, nil, config...)
+}
+
+func (c *Client) UseRequestInterceptor(onFulfilled func(*http.Request) error, onRejected func(*http.Request, error) error, config ...*InterceptorConfig) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+
+	c.registry.sequence++
+	c.registry.requestInterceptors.add(requestInterceptor{onFulfilled: onFulfilled, onRejected: onRejected}, config, c.registry.sequence)
+}
+
+func (c *Client) AddResponseInterceptor(interceptor func(*http.Response) error, config ...*InterceptorConfig) {
+	c.UseResponseInterceptor(interceptor, nil, config...)
+}
+
+func (c *Client) UseResponseInterceptor(onFulfilled func(*http.Response) error, onRejected func(*http.Response, error) error, config ...*InterceptorConfig) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+
+	c.registry.sequence++
+	c.registry.responseInterceptors.add(responseInterceptor{onFulfilled: onFulfilled, onRejected: onRejected}, config, c.registry.sequence)
+}
+
+func (c *Client) AddResponseBodyInterceptor(interceptor func(*Response) (*Response, error), config ...*InterceptorConfig) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+
+	c.registry.sequence++
+	c.registry.bodyInterceptors.add(interceptor, config, c.registry.sequence)
+}
+
+func (c *Client) AddErrorInterceptor(interceptor func(*RequestOptions, error) (*Response, error), config ...*InterceptorConfig) {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+
+	c.registry.sequence++
+	c.registry.errorInterceptors.add(interceptor, config, c.registry.sequence)
+}
+
+func (c *Client) ClearInterceptors() {
+	c.interceptorsMu.Lock()
+	defer c.interceptorsMu.Unlock()
+
+	c.registry = interceptorRegistry{}
+}
+
+func AddOptionsInterceptor(interceptor func(*RequestOptions) error, config ...*InterceptorConfig) {
+	defaultClient.AddOptionsInterceptor(interceptor, config...)
+}
+
+func AddRequestInterceptor(interceptor func(*http.Request) error, config ...*InterceptorConfig) {
+	defaultClient.AddRequestInterceptor(interceptor, config...)
+}
+
+func UseRequestInterceptor(onFulfilled func(*http.Request) error, onRejected func(*http.Request, error) error, config ...*InterceptorConfig) {
+	defaultClient.UseRequestInterceptor(onFulfilled, onRejected, config...)
+}
+
+func AddResponseInterceptor(interceptor func(*http.Response) error, config ...*InterceptorConfig) {
+	defaultClient.AddResponseInterceptor(interceptor, config...)
+}
+
+func UseResponseInterceptor(onFulfilled func(*http.Response) error, onRejected func(*http.Response, error) error, config ...*InterceptorConfig) {
+	defaultClient.UseResponseInterceptor(onFulfilled, onRejected, config...)
+}
+
+func AddResponseBodyInterceptor(interceptor func(*Response) (*Response, error), config ...*InterceptorConfig) {
+	defaultClient.AddResponseBodyInterceptor(interceptor, config...)
+}
+
+func AddErrorInterceptor(interceptor func(*RequestOptions, error) (*Response, error), config ...*InterceptorConfig) {
+	defaultClient.AddErrorInterceptor(interceptor, config...)
+}
+
+func ClearInterceptors() {
+	defaultClient.ClearInterceptors()
+}
+
+func (c *Client) interceptors(options *RequestOptions) interceptorChain {
+	c.interceptorsMu.RLock()
+	defer c.interceptorsMu.RUnlock()
+
+	requestInterceptors := make([]requestInterceptor, len(options.InterceptorOptions.RequestInterceptors))
+	for i, interceptor := range options.InterceptorOptions.RequestInterceptors {
+		requestInterceptors[i] = requestInterceptor{onFulfilled: interceptor}
+	}
+	responseInterceptors := make([]responseInterceptor, len(options.InterceptorOptions.ResponseInterceptors))
+	for i, interceptor := range options.InterceptorOptions.ResponseInterceptors {
+		responseInterceptors[i] = responseInterceptor{onFulfilled: interceptor}
+	}
+
+	order := c.registry.sequence + 1
+	timings := c.interceptorTimings()
+	return interceptorChain{
+		options:  c.registry.optionsInterceptors.merge(options, options.InterceptorOptions.OptionsInterceptors, order, timings.options()),
+		request:  c.registry.requestInterceptors.merge(options, requestInterceptors, order, timings.request()),
+		response: c.registry.responseInterceptors.merge(options, responseInterceptors, order, timings.response()),
+		body:     c.registry.bodyInterceptors.merge(options, options.InterceptorOptions.ResponseBodyInterceptors, order, timings.body()),
+		errors:   c.registry.errorInterceptors.merge(options, options.InterceptorOptions.ErrorInterceptors, order, timings.errors()),
+	}
+}
+
+type interceptorTimer struct {
+	report func(InterceptorTiming)
+}
+
+func (c *Client) interceptorTimings() *interceptorTimer {
+	if c.OnInterceptorTiming == nil {
+		return nil
+	}
+	return &interceptorTimer{report: c.OnInterceptorTiming}
+}
+
+func (t *interceptorTimer) observe(phase, name string, position *int) func(time.Time, error) {
+	*position++
+	if name == "" {
+		name = fmt.Sprintf("%s#%d", phase, *position)
+	}
+	return func(start time.Time, err error) {
+		t.report(InterceptorTiming{Name: name, Phase: phase, Duration: time.Since(start), Err: err})
+	}
+}
+
+func (t *interceptorTimer) options() func(func(*RequestOptions) error, string) func(*RequestOptions) error {
+	if t == nil {
+		return nil
+	}
+	position := 0
+	return func(handler func(*RequestOptions) error, name string) func(*RequestOptions) error {
+		done := t.observe("options", name, &position)
+		return func(options *RequestOptions) error {
+			start := time.Now()
+			err := handler(options)
+			done(start, err)
+			return err
+		}
+	}
+}
+
+func (t *interceptorTimer) request() func(requestInterceptor, string) requestInterceptor {
+	if t == nil {
+		return nil
+	}
+	position := 0
+	return func(handler requestInterceptor, name string) requestInterceptor {
+		done := t.observe("request", name, &position)
+		timed := requestInterceptor{}
+		if handler.onFulfilled != nil {
+			timed.onFulfilled = func(req *http.Request) error {
+				start := time.Now()
+				err := handler.onFulfilled(req)
+				done(start, err)
+				return err
+			}
+		}
+		if handler.onRejected != nil {
+			timed.onRejected = func(req *http.Request, cause error) error {
+				start := time.Now()
+				err := handler.onRejected(req, cause)
+				done(start, err)
+				return err
+			}
+		}
+		return timed
+	}
+}
+
+func (t *interceptorTimer) response() func(responseInterceptor, string) responseInterceptor {
+	if t == nil {
+		return nil
+	}
+	position := 0
+	return func(handler responseInterceptor, name string) responseInterceptor {
+		done := t.observe("response", name, &position)
+		timed := responseInterceptor{}
+		if handler.onFulfilled != nil {
+			timed.onFulfilled = func(resp *http.Response) error {
+				start := time.Now()
+				err := handler.onFulfilled(resp)
+				done(start, err)
+				return err
+			}
+		}
+		if handler.onRejected != nil {
+			timed.onRejected = func(resp *http.Response, cause error) error {
+				start := time.Now()
+				err := handler.onRejected(resp, cause)
+				done(start, err)
+				return err
+			}
+		}
+		return timed
+	}
+}
+
+func (t *interceptorTimer) body() func(func(*Response) (*Response, error), string) func(*Response) (*Response, error) {
+	if t == nil {
+		return nil
+	}
+	position := 0
+	return func(handler func(*Response) (*Response, error), name string) func(*Response) (*Response, error) {
+		done := t.observe("body", name, &position)
+		return func(resp *Response) (*Response, error) {
+			start := time.Now()
+			result, err := handler(resp)
+			done(start, err)
+			return result, err
+		}
+	}
+}
+
+func (t *interceptorTimer) errors() func(func(*RequestOptions, error) (*Response, error), string) func(*RequestOptions, error) (*Response, error) {
+	if t == nil {
+		return nil
+	}
+	position := 0
+	return func(handler func(*RequestOptions, error) (*Response, error), name string) func(*RequestOptions, error) (*Response, error) {
+		done := t.observe("error", name, &position)
+		return func(options *RequestOptions, cause error) (*Response, error) {
+			start := time.Now()
+			resp, err := handler(options, cause)
+			done(start, err)
+			return resp, err
+		}
+	}
+}
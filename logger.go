@@ -2,11 +2,14 @@ package axios4go
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,18 +29,67 @@ type Logger interface {
 	SetLevel(LogLevel)
 }
 
+type DiagnosticLogger interface {
+	LogDiagnostic(string, LogLevel)
+}
+
+// RequestErrorLogger is implemented by loggers that log errors together with
+// the failed request, e.g. to include its correlation ID. The client uses it
+// instead of LogError when available.
+type RequestErrorLogger interface {
+	LogRequestError(*http.Request, error, LogLevel)
+}
+
+// EventLogger is implemented by loggers that log the retries and cache
+// decisions behind a request, which explain why it was sent again or not
+// sent at all.
+type EventLogger interface {
+	LogRetry(RetryInfo, LogLevel)
+	LogCache(CacheEvent, LogLevel)
+}
+
+// CacheDecision is what the cache did for a request.
+type CacheDecision string
+
+const (
+	CacheDecisionHit   CacheDecision = "HIT"
+	CacheDecisionStale CacheDecision = "STALE"
+	CacheDecisionMiss  CacheDecision = "MISS"
+	CacheDecisionStore CacheDecision = "STORE"
+)
+
+// CacheEvent describes a cache decision. Age is set for hits and stale
+// hits, and TTL for stored entries.
+type CacheEvent struct {
+	Decision CacheDecision
+	Key      string
+	URL      string
+	Age      time.Duration
+	TTL      time.Duration
+}
+
+// LogOptions configures a DefaultLogger. MaskBodyFields lists JSON fields
+// whose values are logged as [MASKED]: "password" masks every field named
+// password, and "card.number" every number field inside a card object, at
+// any depth and inside arrays. Credential headers are always masked, in the
+// logged headers and in the curl command IncludeCurl adds for each request.
+// Clock, when set, supplies the timestamps.
 type LogOptions struct {
 	Level          LogLevel
 	MaxBodyLength  int
 	MaskHeaders    []string
+	MaskBodyFields []string
 	Output         io.Writer
 	TimeFormat     string
 	IncludeBody    bool
 	IncludeHeaders bool
+	IncludeCurl    bool
+	Clock          Clock
 }
 
 type DefaultLogger struct {
 	options LogOptions
+	level   atomic.Int64
 }
 
 func NewDefaultLogger(options LogOptions) *DefaultLogger {
@@ -50,27 +102,102 @@ func NewDefaultLogger(options LogOptions) *DefaultLogger {
 	if options.MaxBodyLength == 0 {
 		options.MaxBodyLength = 1000
 	}
-	return &DefaultLogger{options: options}
+	l := &DefaultLogger{options: options}
+	l.level.Store(int64(options.Level))
+	return l
 }
 
+// SetLevel changes the logger's level; it is safe to call while requests
+// are being logged.
 func (l *DefaultLogger) SetLevel(level LogLevel) {
-	l.options.Level = level
+	l.level.Store(int64(level))
+}
+
+// enabled reports whether messages of a request with the given LogLevel
+// are logged. A logger at LevelNone logs nothing.
+func (l *DefaultLogger) enabled(level LogLevel) bool {
+	threshold := LogLevel(l.level.Load())
+	return threshold != LevelNone && level <= threshold
+}
+
+// RequestLogOptions overrides a DefaultLogger's LogOptions for a single
+// request. IncludeBody, IncludeHeaders and IncludeCurl replace the logger's
+// settings when set, a positive MaxBodyLength replaces it, and MaskHeaders
+// and MaskBodyFields are added to the logger's lists.
+type RequestLogOptions struct {
+	IncludeBody    *bool
+	IncludeHeaders *bool
+	IncludeCurl    *bool
+	MaxBodyLength  int
+	MaskHeaders    []string
+	MaskBodyFields []string
+}
+
+// Bool returns a pointer to v, for the optional fields of
+// RequestLogOptions.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// requestOptions returns the logger's options with the overrides that
+// RequestOptions.LogOptions set for req. The headers that carry credentials
+// in req are always masked.
+func (l *DefaultLogger) requestOptions(req *http.Request) LogOptions {
+	options := l.options
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	options.MaskHeaders = append(append([]string{}, options.MaskHeaders...), credentialHeaderNames(ctx)...)
+	override, ok := ctx.Value(logOptionsKey{}).(*RequestLogOptions)
+	if !ok {
+		return options
+	}
+	if override.IncludeBody != nil {
+		options.IncludeBody = *override.IncludeBody
+	}
+	if override.IncludeHeaders != nil {
+		options.IncludeHeaders = *override.IncludeHeaders
+	}
+	if override.IncludeCurl != nil {
+		options.IncludeCurl = *override.IncludeCurl
+	}
+	if override.MaxBodyLength > 0 {
+		options.MaxBodyLength = override.MaxBodyLength
+	}
+	options.MaskHeaders = append(options.MaskHeaders, override.MaskHeaders...)
+	options.MaskBodyFields = append(append([]string{}, options.MaskBodyFields...), override.MaskBodyFields...)
+	return options
+}
+
+type logOptionsKey struct{}
+
+// withLogOptions attaches a request's LogOptions to its context, where
+// DefaultLogger finds them.
+func withLogOptions(ctx context.Context, options *RequestLogOptions) context.Context {
+	if options == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, logOptionsKey{}, options)
 }
 
 func (l *DefaultLogger) LogRequest(req *http.Request, level LogLevel) {
-	if level > l.options.Level {
+	if !l.enabled(level) {
 		return
 	}
+	options := l.requestOptions(req)
 
 	var buf strings.Builder
-	timestamp := time.Now().Format(l.options.TimeFormat)
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
 
-	fmt.Fprintf(&buf, "[%s] REQUEST: %s %s\n", timestamp, req.Method, req.URL)
+	fmt.Fprintf(&buf, "[%s] REQUEST: %s %s", timestamp, req.Method, requestURL(req))
+	writeCorrelationID(&buf, req)
+	buf.WriteString("\n")
 
-	if l.options.IncludeHeaders {
+	if options.IncludeHeaders {
 		buf.WriteString("Headers:\n")
 		for key, vals := range req.Header {
-			if l.isHeaderMasked(key) {
+			if isMasked(key, options.MaskHeaders) {
 				fmt.Fprintf(&buf, "  %s: [MASKED]\n", key)
 			} else {
 				fmt.Fprintf(&buf, "  %s: %s\n", key, strings.Join(vals, ", "))
@@ -78,36 +205,48 @@ func (l *DefaultLogger) LogRequest(req *http.Request, level LogLevel) {
 		}
 	}
 
-	if l.options.IncludeBody && req.Body != nil {
-		body, err := io.ReadAll(req.Body)
+	var body []byte
+	if (options.IncludeBody || options.IncludeCurl) && req.Body != nil {
+		read, err := io.ReadAll(req.Body)
 		if err == nil {
-			req.Body = io.NopCloser(bytes.NewBuffer(body))
-			if len(body) > l.options.MaxBodyLength {
-				fmt.Fprintf(&buf, "Body: (truncated) %s...\n", body[:l.options.MaxBodyLength])
-			} else {
-				fmt.Fprintf(&buf, "Body: %s\n", body)
-			}
+			req.Body = io.NopCloser(bytes.NewBuffer(read))
+			body = maskBody(read, options.MaskBodyFields)
+		}
+	}
+
+	if options.IncludeBody && body != nil {
+		if len(body) > options.MaxBodyLength {
+			fmt.Fprintf(&buf, "Body: (truncated) %s...\n", body[:options.MaxBodyLength])
+		} else {
+			fmt.Fprintf(&buf, "Body: %s\n", body)
 		}
 	}
 
+	if options.IncludeCurl {
+		fmt.Fprintf(&buf, "Curl: %s\n", curlCommand(req, body, options.MaskHeaders))
+	}
+
 	fmt.Fprintln(l.options.Output, buf.String())
 }
 
 func (l *DefaultLogger) LogResponse(resp *http.Response, body []byte, duration time.Duration, level LogLevel) {
-	if level > l.options.Level {
+	if !l.enabled(level) {
 		return
 	}
+	options := l.requestOptions(resp.Request)
 
 	var buf strings.Builder
-	timestamp := time.Now().Format(l.options.TimeFormat)
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
 
-	fmt.Fprintf(&buf, "[%s] RESPONSE: %d %s (%.2fms)\n",
+	fmt.Fprintf(&buf, "[%s] RESPONSE: %d %s (%.2fms)",
 		timestamp, resp.StatusCode, resp.Status, float64(duration.Microseconds())/1000)
+	writeCorrelationID(&buf, resp.Request)
+	buf.WriteString("\n")
 
-	if l.options.IncludeHeaders {
+	if options.IncludeHeaders {
 		buf.WriteString("Headers:\n")
 		for key, vals := range resp.Header {
-			if l.isHeaderMasked(key) {
+			if isMasked(key, options.MaskHeaders) {
 				fmt.Fprintf(&buf, "  %s: [MASKED]\n", key)
 			} else {
 				fmt.Fprintf(&buf, "  %s: %s\n", key, strings.Join(vals, ", "))
@@ -115,9 +254,10 @@ func (l *DefaultLogger) LogResponse(resp *http.Response, body []byte, duration t
 		}
 	}
 
-	if l.options.IncludeBody && body != nil {
-		if len(body) > l.options.MaxBodyLength {
-			fmt.Fprintf(&buf, "Body: (truncated) %s...\n", body[:l.options.MaxBodyLength])
+	if options.IncludeBody && body != nil {
+		body = maskBody(body, options.MaskBodyFields)
+		if len(body) > options.MaxBodyLength {
+			fmt.Fprintf(&buf, "Body: (truncated) %s...\n", body[:options.MaxBodyLength])
 		} else {
 			fmt.Fprintf(&buf, "Body: %s\n", body)
 		}
@@ -127,24 +267,165 @@ func (l *DefaultLogger) LogResponse(resp *http.Response, body []byte, duration t
 }
 
 func (l *DefaultLogger) LogError(err error, level LogLevel) {
-	if level > l.options.Level {
+	if !l.enabled(level) {
 		return
 	}
 
-	timestamp := time.Now().Format(l.options.TimeFormat)
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
 	fmt.Fprintf(l.options.Output, "[%s] ERROR: %v\n", timestamp, err)
 }
 
-func (l *DefaultLogger) isHeaderMasked(header string) bool {
-	header = strings.ToLower(header)
-	for _, masked := range l.options.MaskHeaders {
-		if strings.ToLower(masked) == header {
+func (l *DefaultLogger) LogRequestError(req *http.Request, err error, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var buf strings.Builder
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
+	fmt.Fprintf(&buf, "[%s] ERROR: %v", timestamp, err)
+	writeCorrelationID(&buf, req)
+	fmt.Fprintln(l.options.Output, buf.String())
+}
+
+func (l *DefaultLogger) LogRetry(info RetryInfo, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
+	fmt.Fprintf(l.options.Output, "[%s] RETRY: %s %s attempt %d/%d after %v: %s\n",
+		timestamp, info.Method, info.URL, info.Attempt, info.MaxAttempts, info.Delay, info.Reason)
+}
+
+func (l *DefaultLogger) LogCache(event CacheEvent, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var buf strings.Builder
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
+	fmt.Fprintf(&buf, "[%s] CACHE %s key=%s", timestamp, event.Decision, event.Key)
+	switch event.Decision {
+	case CacheDecisionHit, CacheDecisionStale:
+		fmt.Fprintf(&buf, " age=%v", event.Age.Round(time.Millisecond))
+	case CacheDecisionStore:
+		fmt.Fprintf(&buf, " ttl=%v", event.TTL)
+	}
+	fmt.Fprintln(l.options.Output, buf.String())
+}
+
+func writeCorrelationID(buf *strings.Builder, req *http.Request) {
+	if kind, id := correlationID(req); id != "" {
+		fmt.Fprintf(buf, " (%s: %s)", kind, id)
+	}
+}
+
+func (l *DefaultLogger) LogDiagnostic(message string, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	timestamp := clockNow(l.options.Clock).Format(l.options.TimeFormat)
+	fmt.Fprintf(l.options.Output, "[%s] DIAGNOSTIC: %s\n", timestamp, message)
+}
+
+// maskBody returns body with the values of fields replaced, or body itself
+// if there are none or it isn't JSON.
+func maskBody(body []byte, fields []string) []byte {
+	if len(fields) == 0 {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	paths := make([][]string, len(fields))
+	for i, field := range fields {
+		paths[i] = strings.Split(field, ".")
+	}
+	masked, err := json.Marshal(maskFields(value, nil, paths))
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+// maskFields replaces the values of object fields whose path ends with one
+// of paths. Array elements share their array's path.
+func maskFields(value interface{}, path []string, paths [][]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			fieldPath := append(path[:len(path):len(path)], key)
+			if hasPathSuffix(fieldPath, paths) {
+				v[key] = "[MASKED]"
+			} else {
+				v[key] = maskFields(field, fieldPath, paths)
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = maskFields(elem, path, paths)
+		}
+	}
+	return value
+}
+
+func hasPathSuffix(path []string, suffixes [][]string) bool {
+	for _, suffix := range suffixes {
+		if len(suffix) > len(path) {
+			continue
+		}
+		matched := true
+		for i, name := range suffix {
+			if !strings.EqualFold(path[len(path)-len(suffix)+i], name) {
+				matched = false
+				break
+			}
+		}
+		if matched {
 			return true
 		}
 	}
 	return false
 }
 
+// logError logs err together with req when the logger supports it.
+func (c *Client) logError(logger Logger, req *http.Request, err error, level LogLevel) {
+	if requestLogger, ok := logger.(RequestErrorLogger); ok {
+		requestLogger.LogRequestError(req, err, level)
+	} else {
+		logger.LogError(err, level)
+	}
+}
+
+// logRetry logs a retry when the logger supports it.
+func (c *Client) logRetry(logger Logger, info RetryInfo, level LogLevel) {
+	if events, ok := logger.(EventLogger); ok {
+		events.LogRetry(info, level)
+	}
+}
+
+// logCache logs a cache decision when the logger supports it. The URL is
+// redacted first.
+func (c *Client) logCache(logger Logger, event CacheEvent, level LogLevel) {
+	if events, ok := logger.(EventLogger); ok {
+		event.URL = redactURL(event.URL)
+		events.LogCache(event, level)
+	}
+}
+
+// logger returns the client's logger. SetLogger may replace the default
+// client's logger while requests are in flight, so it is read under
+// loggerMu.
+func (c *Client) logger() Logger {
+	c.loggerMu.RLock()
+	defer c.loggerMu.RUnlock()
+	return c.Logger
+}
+
 func NewLogger(level LogLevel) Logger {
 	return NewDefaultLogger(LogOptions{
 		Level:          level,
@@ -0,0 +1,85 @@
+package axios4go
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxSampledEndpoints bounds the endpoints LogSampling counts requests for,
+// so paths with IDs in them don't grow the counters forever. An endpoint
+// dropped from the counters starts over, with its next request logged.
+const maxSampledEndpoints = 1024
+
+// LogSampling thins out the logs of busy clients. Of the requests to each
+// endpoint (method, host and path) only one in Every is logged, so rarely
+// called endpoints still show up. Endpoint, when set, names the endpoint of
+// a request instead, for example to group "/users/42" and "/users/43" under
+// "GET /users/{id}". Requests that fail, get a 4xx or 5xx status or take
+// longer than SlowThreshold are always logged; their request line is
+// written once the outcome is known.
+type LogSampling struct {
+	Every         int
+	SlowThreshold time.Duration
+	Endpoint      func(*http.Request) string
+
+	mu       sync.Mutex
+	counters map[string]*list.Element
+	lru      *list.List
+}
+
+type sampledEndpoint struct {
+	endpoint string
+	count    uint64
+}
+
+// sample reports whether req is one of the requests logged up front.
+func (s *LogSampling) sample(req *http.Request) bool {
+	if s == nil || s.Every <= 1 {
+		return true
+	}
+	endpoint := req.Method + " " + req.URL.Host + req.URL.Path
+	if s.Endpoint != nil {
+		endpoint = s.Endpoint(req)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counters == nil {
+		s.counters = make(map[string]*list.Element)
+		s.lru = list.New()
+	}
+	elem, ok := s.counters[endpoint]
+	if ok {
+		s.lru.MoveToFront(elem)
+	} else {
+		elem = s.lru.PushFront(&sampledEndpoint{endpoint: endpoint})
+		s.counters[endpoint] = elem
+		for s.lru.Len() > maxSampledEndpoints {
+			delete(s.counters, s.lru.Remove(s.lru.Back()).(*sampledEndpoint).endpoint)
+		}
+	}
+	counter := elem.Value.(*sampledEndpoint)
+	counter.count++
+	return (counter.count-1)%uint64(s.Every) == 0
+}
+
+// keep reports whether a response that wasn't sampled is logged anyway.
+func (s *LogSampling) keep(status int, duration time.Duration) bool {
+	return status >= 400 || (s.SlowThreshold > 0 && duration > s.SlowThreshold)
+}
+
+// logLateRequest logs a request that was held back by sampling, with its
+// already sent body restored from body.
+func (c *Client) logLateRequest(logger Logger, req *http.Request, body []byte, level LogLevel) {
+	logged := req.Clone(req.Context())
+	logged.Body = nil
+	if body != nil {
+		logged.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	logger.LogRequest(logged, level)
+}
@@ -0,0 +1,193 @@
+package axios4go
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives an observation for every completed request, so any
+// telemetry backend can be plugged in through Client.Metrics.
+// ObserveRequest is called synchronously once Request returns its result.
+type Metrics interface {
+	ObserveRequest(info RequestInfo)
+}
+
+// RequestInfo describes a completed request. StatusCode is 0 when no
+// response was received. Attempts counts the times the request was sent to
+// the origin, which is 0 for cache hits and 2 after a token refresh retry.
+// Timing and ConnReused cover the last attempt.
+type RequestInfo struct {
+	Method        string
+	URL           string
+	StatusCode    int
+	Err           error
+	Duration      time.Duration
+	Attempts      int
+	FromCache     bool
+	Stale         bool
+	RequestBytes  int64
+	ResponseBytes int64
+	ConnReused    bool
+	Timing        RequestTiming
+}
+
+// ConnStats counts the connections a client's requests used, to detect
+// keep-alive misconfiguration: a healthy client reuses most connections.
+type ConnStats struct {
+	NewConns      int64
+	ReusedConns   int64
+	TLSHandshakes int64
+}
+
+type connCounters struct {
+	newConns      atomic.Int64
+	reusedConns   atomic.Int64
+	tlsHandshakes atomic.Int64
+}
+
+// ConnStats returns the connection counters of all requests the client has
+// sent so far.
+func (c *Client) ConnStats() ConnStats {
+	return ConnStats{
+		NewConns:      c.connCounters.newConns.Load(),
+		ReusedConns:   c.connCounters.reusedConns.Load(),
+		TLSHandshakes: c.connCounters.tlsHandshakes.Load(),
+	}
+}
+
+// connTrace counts the connection of a request in the client's ConnStats
+// and records in reused whether it was a kept-alive one.
+func (c *Client) connTrace(reused *atomic.Bool) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused.Store(info.Reused)
+			if info.Reused {
+				c.connCounters.reusedConns.Add(1)
+			} else {
+				c.connCounters.newConns.Add(1)
+			}
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				c.connCounters.tlsHandshakes.Add(1)
+			}
+		},
+	}
+}
+
+// RequestTiming breaks down where the time of an attempt went. Phases that
+// didn't happen, such as DNS and Connect on a reused connection, are zero.
+// TimeToFirstByte is measured from the start of the attempt.
+type RequestTiming struct {
+	DNS             time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+}
+
+// requestStats collects what RequestInfo reports while a request runs.
+type requestStats struct {
+	mu           sync.Mutex
+	attempts     int
+	requestBytes int64
+	start        time.Time
+	timing       RequestTiming
+}
+
+// attempt records that the request is sent again and returns the trace
+// that times it.
+func (s *requestStats) attempt(bodyBytes int) *httptrace.ClientTrace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	s.requestBytes = int64(bodyBytes)
+	s.timing = RequestTiming{}
+	s.start = time.Now()
+
+	start := s.start
+	var dnsStart, connectStart, tlsStart time.Time
+	record := func(fn func()) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		fn()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { record(func() { dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(func() { s.timing.DNS = time.Since(dnsStart) }) },
+		ConnectStart: func(string, string) {
+			record(func() {
+				if connectStart.IsZero() {
+					connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(string, string, error) {
+			record(func() { s.timing.Connect = time.Since(connectStart) })
+		},
+		TLSHandshakeStart: func() { record(func() { tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() { s.timing.TLSHandshake = time.Since(tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { s.timing.TimeToFirstByte = time.Since(start) })
+		},
+	}
+}
+
+// last returns when the last attempt started and its timing.
+func (s *requestStats) last() (time.Time, RequestTiming) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.start, s.timing
+}
+
+// observe runs the request with stats attached and reports it to
+// c.Metrics. The options are copied so the stats stay with this call.
+func (c *Client) observe(options *RequestOptions) (*Response, error) {
+	start := time.Now()
+	opts := *options
+	opts.stats = &requestStats{}
+	resp, err := c.Request(&opts)
+
+	info := RequestInfo{
+		Method:   strings.ToUpper(opts.Method),
+		URL:      opts.URL,
+		Err:      err,
+		Duration: time.Since(start),
+	}
+	if info.Method == "" {
+		info.Method = "GET"
+	}
+	if fullURL, urlErr := c.buildURL(&opts); urlErr == nil {
+		info.URL = redactURL(fullURL)
+	}
+	var httpErr *HTTPError
+	switch {
+	case resp != nil:
+		info.StatusCode = resp.StatusCode
+		info.ConnReused = resp.ConnReused
+		info.FromCache = resp.FromCache
+		info.Stale = resp.Stale
+		info.ResponseBytes = int64(len(resp.Body))
+	case errors.As(err, &httpErr):
+		info.StatusCode = httpErr.StatusCode
+		if httpErr.Response != nil {
+			info.ConnReused = httpErr.Response.ConnReused
+			info.ResponseBytes = int64(len(httpErr.Response.Body))
+		}
+	}
+	stats := opts.stats
+	stats.mu.Lock()
+	info.Attempts = stats.attempts
+	info.RequestBytes = stats.requestBytes
+	info.Timing = stats.timing
+	stats.mu.Unlock()
+
+	c.Metrics.ObserveRequest(info)
+	return resp, err
+}
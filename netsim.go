@@ -0,0 +1,140 @@
+package axios4go
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// NetworkConditions describe a simulated connection. Latency, plus or
+// minus a random Jitter, delays every request before it is sent, and the
+// bandwidths cap how fast request and response bodies are transferred,
+// in bytes per second. Zero values don't slow anything down.
+type NetworkConditions struct {
+	Latency                time.Duration
+	Jitter                 time.Duration
+	UploadBytesPerSecond   int64
+	DownloadBytesPerSecond int64
+}
+
+// SimulatedNetwork is an Adapter that slows requests down to the
+// conditions of their host, for testing services under a realistic slow
+// network during development. It is not meant for production traffic.
+//
+//	client.Adapter = &axios4go.SimulatedNetwork{
+//		Default: axios4go.NetworkConditions{Latency: 100 * time.Millisecond, Jitter: 30 * time.Millisecond},
+//		Hosts: map[string]axios4go.NetworkConditions{
+//			"legacy.example.com": {Latency: time.Second, DownloadBytesPerSecond: 50_000},
+//		},
+//	}
+//
+// Hosts are looked up with their port first, then without it, and other
+// hosts get Default. Next sends the slowed requests and defaults to an
+// http.Client with default settings. The request's Timeout covers the
+// simulated delays, so slow hosts can make requests time out.
+type SimulatedNetwork struct {
+	Default NetworkConditions
+	Hosts   map[string]NetworkConditions
+	Next    Adapter
+}
+
+func (n *SimulatedNetwork) Do(req *http.Request, options *RequestOptions) (*http.Response, error) {
+	conditions := n.conditions(req)
+	ctx := req.Context()
+
+	delay := conditions.Latency
+	if conditions.Jitter > 0 {
+		delay += time.Duration((2*rand.Float64() - 1) * float64(conditions.Jitter))
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		return nil, err
+	}
+
+	if conditions.UploadBytesPerSecond > 0 && req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = throttle(ctx, req.Body, conditions.UploadBytesPerSecond)
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return throttle(ctx, body, conditions.UploadBytesPerSecond), nil
+			}
+		}
+	}
+
+	next := n.Next
+	if next == nil {
+		next = httpClientAdapter{client: &http.Client{}}
+	}
+	resp, err := next.Do(req, options)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if conditions.DownloadBytesPerSecond > 0 && resp.Body != nil {
+		resp.Body = throttle(ctx, resp.Body, conditions.DownloadBytesPerSecond)
+	}
+	return resp, nil
+}
+
+func (n *SimulatedNetwork) conditions(req *http.Request) NetworkConditions {
+	if conditions, ok := n.Hosts[req.URL.Host]; ok {
+		return conditions
+	}
+	if conditions, ok := n.Hosts[req.URL.Hostname()]; ok {
+		return conditions
+	}
+	return n.Default
+}
+
+// throttledReader reads no faster than rate bytes per second, in chunks of
+// a tenth of a second's worth so progress stays smooth.
+type throttledReader struct {
+	ctx    context.Context
+	reader io.ReadCloser
+	rate   int64
+	start  time.Time
+	read   int64
+}
+
+func throttle(ctx context.Context, reader io.ReadCloser, rate int64) io.ReadCloser {
+	return &throttledReader{ctx: ctx, reader: reader, rate: rate}
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	if chunk := max(r.rate/10, 1); int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	wait := time.Duration(float64(r.read)/float64(r.rate)*float64(time.Second)) - time.Since(r.start)
+	if serr := sleepContext(r.ctx, wait); serr != nil {
+		return n, serr
+	}
+	return n, err
+}
+
+func (r *throttledReader) Close() error {
+	return r.reader.Close()
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package axios4go
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const defaultPrefetchConcurrency = 4
+
+// PrefetchOptions configures Client.Prefetch. Concurrency limits how many
+// requests run at once and defaults to 4.
+type PrefetchOptions struct {
+	Concurrency int
+}
+
+// Prefetch sends requests only to populate the client's cache, for example
+// to warm known-hot endpoints at startup. Requests that don't opt in to
+// caching are cached with the client's DefaultTTL, and requests whose entry
+// is already cached are not sent again. The responses are discarded; the
+// errors of failed requests are joined into the returned error.
+func (c *Client) Prefetch(requests []RequestOptions, options PrefetchOptions) error {
+	if c.CacheConfig == nil || c.CacheConfig.Cache == nil {
+		return fmt.Errorf("%w: Prefetch needs a client with a CacheConfig", ErrInvalidOption)
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPrefetchConcurrency
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	slots := make(chan struct{}, concurrency)
+	for i := range requests {
+		opts := requests[i]
+		if opts.Cache == nil {
+			opts.Cache = &CacheOptions{Enabled: true}
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if _, err := c.Request(&opts); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
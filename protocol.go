@@ -0,0 +1,152 @@
+package axios4go
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	// ProtocolHTTP1 disables HTTP/2 and always speaks HTTP/1.1.
+	ProtocolHTTP1 = "http/1.1"
+	// ProtocolHTTP2 attempts HTTP/2 over TLS even when the transport has
+	// custom TLS or dial settings, falling back to HTTP/1.1.
+	ProtocolHTTP2 = "h2"
+	// ProtocolH2C speaks cleartext HTTP/2 with prior knowledge, as used by
+	// gRPC gateways and service meshes. It only supports http:// URLs.
+	ProtocolH2C = "h2c"
+	// ProtocolHTTP3 tries HTTP/3 over QUIC first and falls back to HTTP/2 or
+	// HTTP/1.1 over TCP when the QUIC attempt fails. It requires importing
+	// github.com/rezmoss/axios4go/http3.
+	ProtocolHTTP3 = "h3"
+)
+
+// newHTTP3Transport is set by RegisterHTTP3.
+var newHTTP3Transport func(tlsConfig *tls.Config) http.RoundTripper
+
+// ErrHTTP3Unavailable is wrapped by errors of the registered HTTP/3
+// transport that happen before a request is sent, such as a failed QUIC
+// handshake. Such requests fall back to TCP whatever their method; others
+// only when their method is idempotent, since the server may have acted on
+// them already.
+var ErrHTTP3Unavailable = errors.New("HTTP/3 connection could not be established")
+
+// RegisterHTTP3 installs the transport ProtocolHTTP3 sends requests with.
+// The github.com/rezmoss/axios4go/http3 package calls it when imported,
+// which keeps quic-go out of programs that don't use HTTP/3. It must be
+// called before requests are sent.
+func RegisterHTTP3(newTransport func(tlsConfig *tls.Config) http.RoundTripper) {
+	newHTTP3Transport = newTransport
+}
+
+func (c *Client) applyProtocol(transport *http.Transport) {
+	switch c.Protocol {
+	case ProtocolHTTP1:
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case ProtocolHTTP2:
+		transport.ForceAttemptHTTP2 = true
+	}
+}
+
+func (c *Client) h2cTransport(socket string) http.RoundTripper {
+	return c.cachedTransport("h2c:"+socket, func() http.RoundTripper {
+		dial := c.dialContext()
+		if socket != "" {
+			dial = unixDial(socket)
+		}
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		return &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+		}
+	})
+}
+
+// http3Transport sends requests over HTTP/3 and falls back to the regular
+// transport. Requests with a proxy or per-request TLS options skip HTTP/3,
+// since the shared QUIC transport only knows the client's TLS settings.
+func (c *Client) http3Transport(options *RequestOptions, fallback http.RoundTripper) (http.RoundTripper, error) {
+	if newHTTP3Transport == nil {
+		return nil, fmt.Errorf("%w: %s requires importing github.com/rezmoss/axios4go/http3", ErrUnsupportedProtocol, ProtocolHTTP3)
+	}
+	if fallback == nil {
+		fallback = c.HTTPClient.Transport
+	}
+	if fallback == nil {
+		fallback = http.DefaultTransport
+	}
+	if options.hasProxy() || c.ProxyFunc != nil || options.TLS != nil || options.TLSCertificate != nil || options.TLSClientCert != "" {
+		return fallback, nil
+	}
+
+	tlsConfig, err := c.tlsConfig(&RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	h3 := c.cachedTransport("h3", func() http.RoundTripper {
+		return newHTTP3Transport(tlsConfig)
+	})
+	return &fallbackTransport{primary: h3, fallback: fallback, broken: &c.http3Broken}, nil
+}
+
+// http3BrokenFor is how long a host whose HTTP/3 attempt failed is sent
+// straight to the fallback transport.
+const http3BrokenFor = 5 * time.Minute
+
+type fallbackTransport struct {
+	primary  http.RoundTripper
+	fallback http.RoundTripper
+	broken   *sync.Map
+}
+
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if until, ok := t.broken.Load(req.URL.Host); ok && time.Now().Before(until.(time.Time)) {
+		return t.fallback.RoundTrip(req)
+	}
+	resp, err := t.primary.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	if req.Context().Err() != nil || errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+	t.broken.Store(req.URL.Host, time.Now().Add(http3BrokenFor))
+	if !replayable(req, err) {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return t.fallback.RoundTrip(retry)
+}
+
+// replayable reports whether a request whose HTTP/3 attempt failed with err
+// can be sent again over TCP.
+func replayable(req *http.Request, err error) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return errors.Is(err, ErrHTTP3Unavailable)
+}
@@ -0,0 +1,128 @@
+package axios4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultMaxRedirects matches the limit net/http applies when no
+// CheckRedirect is set.
+const defaultMaxRedirects = 10
+
+// checkRedirect returns the redirect policy of a request. OnRedirect sees
+// each hop first and may rewrite its target; credentials are then stripped
+// whenever the hop leaves the origin of the original request.
+// RequestOptions.CheckRedirect takes precedence over a CheckRedirect set on
+// HTTPClient, and MaxRedirects is enforced before either runs.
+func (c *Client) checkRedirect(base *http.Client, options *RequestOptions) func(*http.Request, []*http.Request) error {
+	next := base.CheckRedirect
+	if options.CheckRedirect != nil {
+		next = options.CheckRedirect
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if options.DisableRedirects {
+			return http.ErrUseLastResponse
+		}
+		if onRedirect := c.onRedirect(options); onRedirect != nil && req.Response != nil {
+			if err := onRedirect(via[len(via)-1].URL, req.URL, req.Response.StatusCode); err != nil {
+				return err
+			}
+		}
+		if !sameOrigin(via[0].URL, req.URL) {
+			c.stripCredentials(req, options)
+		}
+		if options.KeepMethodOnRedirect {
+			if err := preserveMethod(req, via[0]); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case options.MaxRedirects > 0 && len(via) >= options.MaxRedirects:
+			return fmt.Errorf("%w (max: %d)", ErrTooManyRedirects, options.MaxRedirects)
+		case next != nil:
+			return next(req, via)
+		case options.MaxRedirects <= 0 && len(via) >= defaultMaxRedirects:
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
+
+// preserveMethod resends the original method and body after a 301 or 302,
+// which net/http turns into a GET like browsers do. Bodies that can't be
+// replayed (GetBody is nil) keep the GET.
+func preserveMethod(req, original *http.Request) error {
+	if req.Response == nil || req.Method == original.Method || original.GetBody == nil {
+		return nil
+	}
+	if status := req.Response.StatusCode; status != http.StatusMovedPermanently && status != http.StatusFound {
+		return nil
+	}
+
+	body, err := original.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Method = original.Method
+	req.Body = body
+	req.GetBody = original.GetBody
+	req.ContentLength = original.ContentLength
+	for _, name := range []string{"Content-Type", "Content-Encoding", "Content-Language"} {
+		if value := original.Header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	return nil
+}
+
+func (c *Client) onRedirect(options *RequestOptions) func(from, to *url.URL, status int) error {
+	if options.OnRedirect != nil {
+		return options.OnRedirect
+	}
+	return c.OnRedirect
+}
+
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
+
+// stripCredentials removes headers that carry credentials from a redirect
+// to another origin, unless KeepHeadersOnRedirect lists them. net/http
+// already drops Authorization and Cookie when the redirect leaves the
+// domain, but keeps them for other ports and subdomains and knows nothing of
+// API key or CSRF headers.
+func (c *Client) stripCredentials(req *http.Request, options *RequestOptions) {
+	for _, name := range c.credentialHeaders(options) {
+		if !c.keepOnRedirect(name) {
+			req.Header.Del(name)
+		}
+	}
+}
+
+// credentialHeaders lists the headers that may carry credentials in
+// requests made with options.
+func (c *Client) credentialHeaders(options *RequestOptions) []string {
+	headers := append([]string{}, sensitiveHeaders...)
+	if auth := options.Auth; auth != nil && auth.APIKey != "" && auth.Name != "" {
+		headers = append(headers, auth.Name)
+	}
+	for _, name := range []string{options.XSRFHeaderName, c.XSRFHeaderName, DefaultXSRFHeaderName} {
+		if name != "" {
+			headers = append(headers, name)
+		}
+	}
+	return headers
+}
+
+func (c *Client) keepOnRedirect(name string) bool {
+	for _, keep := range c.KeepHeadersOnRedirect {
+		if strings.EqualFold(keep, name) {
+			return true
+		}
+	}
+	return false
+}
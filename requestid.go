@@ -0,0 +1,55 @@
+package axios4go
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// CorrelationIDHeader is the other common header for IDs that tie together
+// the log lines of one request across services.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+func NewUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("axios4go: failed to generate UUID: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func RequestIDInterceptor(supplier func() string) func(*http.Request) error {
+	if supplier == nil {
+		supplier = NewUUID
+	}
+	return func(req *http.Request) error {
+		if req.Header.Get(RequestIDHeader) == "" {
+			req.Header.Set(RequestIDHeader, supplier())
+		}
+		return nil
+	}
+}
+
+// correlationID returns the ID that identifies req in logs and the kind of
+// ID it is: the X-Request-ID header, else X-Correlation-ID, else the trace
+// ID of a W3C traceparent header.
+func correlationID(req *http.Request) (kind, id string) {
+	if req == nil {
+		return "", ""
+	}
+	if id := req.Header.Get(RequestIDHeader); id != "" {
+		return "request-id", id
+	}
+	if id := req.Header.Get(CorrelationIDHeader); id != "" {
+		return "correlation-id", id
+	}
+	if parts := strings.Split(req.Header.Get("Traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return "trace-id", parts[1]
+	}
+	return "", ""
+}
@@ -0,0 +1,91 @@
+package axios4go
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Transport returns an http.RoundTripper that sends requests through c, so
+// libraries that accept an *http.Client get the client's interceptors,
+// auth, token refresh, caching, logging, metrics and hooks:
+//
+//	sdk := thirdparty.New(&http.Client{Transport: client.Transport()})
+//
+// Every status is returned as a response rather than an error, and the
+// request's URL is used as is, without BaseURL or a body size limit. The
+// request runs in the request's context, so cancelling it stops the request
+// and context values reach interceptors and hooks. Without a deadline on
+// the context, only the Timeout of c's Defaults applies. Don't use the
+// transport in c's own HTTPClient.
+func (c *Client) Transport() http.RoundTripper {
+	return clientTransport{client: c}
+}
+
+type clientTransport struct {
+	client *Client
+}
+
+func (t clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	options, err := roundTripOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Request(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cached responses have no protocol.
+	proto := resp.Protocol
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        resp.Headers,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// roundTripOptions describes req as RequestOptions.
+func roundTripOptions(req *http.Request) (*RequestOptions, error) {
+	options := &RequestOptions{
+		Method:           req.Method,
+		URL:              req.URL.String(),
+		HeaderValues:     req.Header.Clone(),
+		MaxContentLength: math.MaxInt64,
+		MaxBodyLength:    math.MaxInt64,
+		ValidateStatus:   func(int) bool { return true },
+		skipBaseURL:      true,
+		ctx:              req.Context(),
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		options.Host = req.Host
+	}
+	if deadline, ok := req.Context().Deadline(); ok {
+		options.Timeout = max(int(time.Until(deadline).Milliseconds()), 1)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			options.Body = body
+		}
+	}
+	return options, nil
+}
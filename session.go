@@ -0,0 +1,107 @@
+package axios4go
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Session carries state across the requests of a stateful workflow, like
+// logging in and then acting as that user. Cookies set by responses are kept
+// in the client's CookieJar, and Headers are sent with every request unless
+// the request sets the same header itself, in any case. A Session is safe
+// for concurrent use.
+type Session struct {
+	Client *Client
+
+	mu      sync.RWMutex
+	headers map[string]string
+}
+
+// NewSession returns a session whose requests resolve against baseURL and
+// share a fresh cookie jar.
+func NewSession(baseURL string) *Session {
+	return &Session{Client: NewClientWithCookieJar(baseURL), headers: make(map[string]string)}
+}
+
+// SetHeader sets a header sent with every later request of the session.
+func (s *Session) SetHeader(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.headers[http.CanonicalHeaderKey(key)] = value
+}
+
+// DelHeader stops sending a header set with SetHeader.
+func (s *Session) DelHeader(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.headers, http.CanonicalHeaderKey(key))
+}
+
+// Cookies returns the cookies the session would send to its base URL.
+func (s *Session) Cookies() []*http.Cookie {
+	if s.Client.CookieJar == nil {
+		return nil
+	}
+	u, err := url.Parse(s.Client.BaseURL)
+	if err != nil {
+		return nil
+	}
+	return s.Client.CookieJar.Cookies(u)
+}
+
+// Request sends a request with the session's headers and cookies.
+func (s *Session) Request(options *RequestOptions) (*Response, error) {
+	opts := &RequestOptions{}
+	if options != nil {
+		*opts = *options
+	}
+
+	s.mu.RLock()
+	headers := make(map[string]string, len(s.headers)+len(opts.Headers))
+	for key, value := range s.headers {
+		if len(opts.HeaderValues.Values(key)) == 0 {
+			headers[key] = value
+		}
+	}
+	s.mu.RUnlock()
+	for key, value := range opts.Headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	opts.Headers = headers
+
+	return s.Client.Request(opts)
+}
+
+func (s *Session) Get(urlStr string, options ...*RequestOptions) (*Response, error) {
+	return s.do("GET", urlStr, nil, options)
+}
+
+func (s *Session) Post(urlStr string, body interface{}, options ...*RequestOptions) (*Response, error) {
+	return s.do("POST", urlStr, body, options)
+}
+
+func (s *Session) Put(urlStr string, body interface{}, options ...*RequestOptions) (*Response, error) {
+	return s.do("PUT", urlStr, body, options)
+}
+
+func (s *Session) Patch(urlStr string, body interface{}, options ...*RequestOptions) (*Response, error) {
+	return s.do("PATCH", urlStr, body, options)
+}
+
+func (s *Session) Delete(urlStr string, options ...*RequestOptions) (*Response, error) {
+	return s.do("DELETE", urlStr, nil, options)
+}
+
+func (s *Session) do(method, urlStr string, body interface{}, options []*RequestOptions) (*Response, error) {
+	opts := &RequestOptions{}
+	if len(options) > 0 && options[0] != nil {
+		*opts = *options[0]
+	}
+	opts.Method = method
+	opts.URL = urlStr
+	if body != nil {
+		opts.Body = body
+	}
+	return s.Request(opts)
+}
@@ -0,0 +1,72 @@
+package axios4go
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
+
+const (
+	DefaultKeyIDHeader     = "X-Key-Id"
+	DefaultSignatureHeader = "X-Signature"
+)
+
+// Signer signs an outgoing request. It runs after all headers, including
+// Auth, have been set, and receives the exact request body bytes (nil when
+// the request has no body).
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignerFunc adapts a plain function to the Signer interface.
+type SignerFunc func(req *http.Request, body []byte) error
+
+func (f SignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// HMACSigner signs the request body with HMAC-SHA256, the scheme used by most
+// webhook-style APIs. The key id is sent in KeyIDHeader and the signature, as
+// "sha256=<hex>", in SignatureHeader.
+type HMACSigner struct {
+	KeyID           string
+	Secret          []byte
+	KeyIDHeader     string
+	SignatureHeader string
+}
+
+func NewHMACSigner(keyID string, secret []byte) *HMACSigner {
+	return &HMACSigner{
+		KeyID:           keyID,
+		Secret:          secret,
+		KeyIDHeader:     DefaultKeyIDHeader,
+		SignatureHeader: DefaultSignatureHeader,
+	}
+}
+
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	if len(s.Secret) == 0 {
+		return errors.New("hmac signer has no secret")
+	}
+
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	signatureHeader := s.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = DefaultSignatureHeader
+	}
+	req.Header.Set(signatureHeader, signature)
+
+	if s.KeyID != "" {
+		keyIDHeader := s.KeyIDHeader
+		if keyIDHeader == "" {
+			keyIDHeader = DefaultKeyIDHeader
+		}
+		req.Header.Set(keyIDHeader, s.KeyID)
+	}
+	return nil
+}
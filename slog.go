@@ -0,0 +1,163 @@
+package axios4go
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// SlogLogger is a Logger that writes structured records to a *slog.Logger,
+// with the method, URL, status, duration and size as attributes instead of
+// free text. Requests and responses are logged at Debug for requests with
+// LogLevel LevelDebug and at Info otherwise; errors are logged at Error.
+// The level set with SetLevel filters like DefaultLogger's, before the
+// handler's own level applies.
+type SlogLogger struct {
+	logger *slog.Logger
+	level  atomic.Int64
+}
+
+// NewSlogLogger returns a Logger backed by logger, or by slog.Default() if
+// logger is nil.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	l := &SlogLogger{logger: logger}
+	l.level.Store(int64(LevelDebug))
+	return l
+}
+
+func (l *SlogLogger) SetLevel(level LogLevel) {
+	l.level.Store(int64(level))
+}
+
+func (l *SlogLogger) enabled(level LogLevel) bool {
+	threshold := LogLevel(l.level.Load())
+	return threshold != LevelNone && level <= threshold
+}
+
+func (l *SlogLogger) LogRequest(req *http.Request, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", requestURL(req)),
+	}
+	if req.ContentLength > 0 {
+		attrs = append(attrs, slog.Int64("bytes", req.ContentLength))
+	}
+	attrs = appendCorrelationID(attrs, req)
+	l.logger.LogAttrs(context.Background(), slogLevel(level), "request", attrs...)
+}
+
+func (l *SlogLogger) LogResponse(resp *http.Response, body []byte, duration time.Duration, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var attrs []slog.Attr
+	if resp.Request != nil {
+		attrs = append(attrs,
+			slog.String("method", resp.Request.Method),
+			slog.String("url", requestURL(resp.Request)),
+		)
+	}
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", duration),
+		slog.Int("bytes", len(body)),
+	)
+	attrs = appendCorrelationID(attrs, resp.Request)
+	l.logger.LogAttrs(context.Background(), slogLevel(level), "response", attrs...)
+}
+
+func (l *SlogLogger) LogError(err error, level LogLevel) {
+	l.LogRequestError(nil, err, level)
+}
+
+func (l *SlogLogger) LogRequestError(req *http.Request, err error, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	var attrs []slog.Attr
+	if req != nil {
+		attrs = append(attrs,
+			slog.String("method", req.Method),
+			slog.String("url", requestURL(req)),
+		)
+	}
+	attrs = append(attrs, slog.String("error", err.Error()))
+	if code := ErrorCodeOf(err); code != "" {
+		attrs = append(attrs, slog.String("code", string(code)))
+	}
+	attrs = appendCorrelationID(attrs, req)
+	l.logger.LogAttrs(context.Background(), slog.LevelError, "request failed", attrs...)
+}
+
+func (l *SlogLogger) LogDiagnostic(message string, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	l.logger.LogAttrs(context.Background(), slog.LevelWarn, "diagnostic", slog.String("message", message))
+}
+
+func (l *SlogLogger) LogRetry(info RetryInfo, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	l.logger.LogAttrs(context.Background(), slog.LevelWarn, "retry",
+		slog.String("method", info.Method),
+		slog.String("url", info.URL),
+		slog.Int("attempt", info.Attempt),
+		slog.Int("max_attempts", info.MaxAttempts),
+		slog.Duration("delay", info.Delay),
+		slog.String("reason", info.Reason),
+	)
+}
+
+func (l *SlogLogger) LogCache(event CacheEvent, level LogLevel) {
+	if !l.enabled(level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("decision", string(event.Decision)),
+		slog.String("key", event.Key),
+		slog.String("url", event.URL),
+	}
+	switch event.Decision {
+	case CacheDecisionHit, CacheDecisionStale:
+		attrs = append(attrs, slog.Duration("age", event.Age))
+	case CacheDecisionStore:
+		attrs = append(attrs, slog.Duration("ttl", event.TTL))
+	}
+	l.logger.LogAttrs(context.Background(), slog.LevelDebug, "cache", attrs...)
+}
+
+// appendCorrelationID adds the request's correlation ID as request_id,
+// correlation_id or trace_id.
+func appendCorrelationID(attrs []slog.Attr, req *http.Request) []slog.Attr {
+	if kind, id := correlationID(req); id != "" {
+		attrs = append(attrs, slog.String(strings.ReplaceAll(kind, "-", "_"), id))
+	}
+	return attrs
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,40 @@
+package axios4go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+type stallReader struct {
+	reader  io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+}
+
+func newStallReader(reader io.Reader, timeout time.Duration, cancel context.CancelFunc) *stallReader {
+	sr := &stallReader{reader: reader, timeout: timeout}
+	sr.timer = time.AfterFunc(timeout, func() {
+		sr.stalled.Store(true)
+		cancel()
+	})
+	return sr
+}
+
+func (sr *stallReader) Read(p []byte) (int, error) {
+	n, err := sr.reader.Read(p)
+	if err != nil && sr.stalled.Load() {
+		return n, fmt.Errorf("%w: no data received for %v", ErrStalledTransfer, sr.timeout)
+	}
+	if n > 0 {
+		sr.timer.Reset(sr.timeout)
+	}
+	return n, err
+}
+
+func (sr *stallReader) stop() {
+	sr.timer.Stop()
+}
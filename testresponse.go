@@ -0,0 +1,128 @@
+package axios4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewTestResponse returns a Response for unit tests of code that consumes
+// axios4go responses, without a server. body is used as is if it is a
+// string or []byte and encoded as JSON otherwise, with a Content-Type of
+// application/json. headers are added to the response. It panics if body
+// can't be encoded.
+func NewTestResponse(status int, body interface{}, headers ...map[string]string) *Response {
+	b := NewResponseBuilder().Status(status)
+	switch v := body.(type) {
+	case nil:
+	case string:
+		b.Body(v)
+	case []byte:
+		b.Body(string(v))
+	default:
+		b.JSON(v)
+	}
+	for _, h := range headers {
+		for key, value := range h {
+			b.Header(key, value)
+		}
+	}
+	return b.Build()
+}
+
+// ResponseBuilder builds Response and CacheEntry values for tests:
+//
+//	resp := axios4go.NewResponseBuilder().
+//		Status(404).
+//		JSON(map[string]string{"error": "not found"}).
+//		Header("Retry-After", "30").
+//		Build()
+type ResponseBuilder struct {
+	resp Response
+}
+
+// NewResponseBuilder returns a builder of a 200 response without a body.
+func NewResponseBuilder() *ResponseBuilder {
+	return &ResponseBuilder{resp: Response{StatusCode: http.StatusOK, Headers: http.Header{}, Protocol: "HTTP/1.1"}}
+}
+
+func (b *ResponseBuilder) Status(status int) *ResponseBuilder {
+	b.resp.StatusCode = status
+	return b
+}
+
+// Header adds a header value.
+func (b *ResponseBuilder) Header(key, value string) *ResponseBuilder {
+	b.resp.Headers.Add(key, value)
+	return b
+}
+
+// RequestID sets the ID the request was sent with.
+func (b *ResponseBuilder) RequestID(id string) *ResponseBuilder {
+	b.resp.RequestID = id
+	return b
+}
+
+func (b *ResponseBuilder) Body(body string) *ResponseBuilder {
+	b.resp.Body = []byte(body)
+	return b
+}
+
+// JSON sets the body to v encoded as JSON and the Content-Type to
+// application/json. It panics if v can't be encoded.
+func (b *ResponseBuilder) JSON(v interface{}) *ResponseBuilder {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("axios4go: encoding test response body: %v", err))
+	}
+	b.resp.Body = body
+	b.resp.Headers.Set("Content-Type", "application/json")
+	return b
+}
+
+// FromCache marks the response as served from the cache under key, stored
+// age ago.
+func (b *ResponseBuilder) FromCache(key string, age time.Duration) *ResponseBuilder {
+	b.resp.FromCache = true
+	b.resp.CacheKey = key
+	b.resp.CacheAge = age
+	return b
+}
+
+// Stale marks the response as served from a stale cache entry.
+func (b *ResponseBuilder) Stale() *ResponseBuilder {
+	b.resp.Stale = true
+	return b
+}
+
+func (b *ResponseBuilder) Protocol(protocol string) *ResponseBuilder {
+	b.resp.Protocol = protocol
+	return b
+}
+
+// Build returns the response. The builder can keep being used; later
+// changes don't affect responses already built.
+func (b *ResponseBuilder) Build() *Response {
+	resp := b.resp
+	resp.Headers = b.resp.Headers.Clone()
+	resp.Body = append([]byte(nil), b.resp.Body...)
+	return &resp
+}
+
+// CacheEntry returns a cache entry holding the response that is fresh for
+// ttl from now, or from age ago if FromCache was used, for testing Cache
+// implementations and code that inspects entries.
+func (b *ResponseBuilder) CacheEntry(ttl time.Duration) *CacheEntry {
+	createdAt := time.Now().Add(-b.resp.CacheAge)
+	expiresAt := createdAt.Add(ttl)
+	return &CacheEntry{
+		StatusCode:      b.resp.StatusCode,
+		Headers:         b.resp.Headers.Clone(),
+		Body:            append([]byte(nil), b.resp.Body...),
+		CreatedAt:       createdAt,
+		ExpiresAt:       expiresAt,
+		StaleAt:         expiresAt,
+		RevalidateUntil: expiresAt,
+	}
+}
@@ -0,0 +1,205 @@
+package axios4go
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures the TLS connection without building a Transport by
+// hand. RootCAs (PEM data) and RootCAFile replace the system roots when set.
+// PinnedSPKI holds base64-encoded SHA-256 hashes of SubjectPublicKeyInfo;
+// when set, the connection is rejected with a *CertificatePinError unless a
+// certificate in the server's chain matches one of them. KeyLogWriter
+// receives TLS secrets in NSS key log format for tools like Wireshark; it
+// compromises the connection's security and is meant for debugging only.
+type TLSOptions struct {
+	RootCAs            []byte
+	RootCAFile         string
+	InsecureSkipVerify bool
+	MinVersion         uint16
+	MaxVersion         uint16
+	CipherSuites       []uint16
+	ServerName         string
+	PinnedSPKI         []string
+	KeyLogWriter       io.Writer
+}
+
+type CertificatePinError struct {
+	Host      string
+	Presented []string
+}
+
+func (e *CertificatePinError) Error() string {
+	if e.Host == "" {
+		return fmt.Sprintf("certificate pin mismatch: presented %s", strings.Join(e.Presented, ", "))
+	}
+	return fmt.Sprintf("certificate pin mismatch for %s: presented %s", e.Host, strings.Join(e.Presented, ", "))
+}
+
+func (e *CertificatePinError) Is(target error) bool {
+	return target == ErrCertificatePin
+}
+
+// SPKIHash returns the base64-encoded SHA-256 hash of a certificate's
+// SubjectPublicKeyInfo, the format used by PinnedSPKI.
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		certs := state.PeerCertificates
+		if len(state.VerifiedChains) > 0 {
+			certs = state.VerifiedChains[0]
+		}
+		presented := make([]string, len(certs))
+		for i, cert := range certs {
+			presented[i] = SPKIHash(cert)
+			for _, pin := range pins {
+				if presented[i] == pin {
+					return nil
+				}
+			}
+		}
+		return &CertificatePinError{Host: state.ServerName, Presented: presented}
+	}
+}
+
+func (o *TLSOptions) apply(config *tls.Config) error {
+	if len(o.RootCAs) > 0 || o.RootCAFile != "" {
+		pool := x509.NewCertPool()
+		if len(o.RootCAs) > 0 && !pool.AppendCertsFromPEM(o.RootCAs) {
+			return fmt.Errorf("%w: no certificates found in RootCAs", ErrInvalidTLSConfig)
+		}
+		if o.RootCAFile != "" {
+			pem, err := os.ReadFile(o.RootCAFile)
+			if err != nil {
+				return fmt.Errorf("%w: reading RootCAFile: %w", ErrInvalidTLSConfig, err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("%w: no certificates found in %s", ErrInvalidTLSConfig, o.RootCAFile)
+			}
+		}
+		config.RootCAs = pool
+	}
+	if o.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+	if o.MinVersion != 0 {
+		config.MinVersion = o.MinVersion
+	}
+	if o.MaxVersion != 0 {
+		config.MaxVersion = o.MaxVersion
+	}
+	if len(o.CipherSuites) > 0 {
+		config.CipherSuites = o.CipherSuites
+	}
+	if o.ServerName != "" {
+		config.ServerName = o.ServerName
+	}
+	if len(o.PinnedSPKI) > 0 {
+		config.VerifyConnection = verifyPins(o.PinnedSPKI)
+	}
+	if o.KeyLogWriter != nil {
+		config.KeyLogWriter = o.KeyLogWriter
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS settings for a request, or nil when neither the
+// request nor the client configures any. RequestOptions.TLS replaces
+// Client.TLS as a whole rather than being merged field by field.
+func (c *Client) tlsConfig(options *RequestOptions) (*tls.Config, error) {
+	cert, err := c.clientCertificate(options)
+	if err != nil {
+		return nil, err
+	}
+	tlsOptions := c.tlsOptions(options)
+	if cert == nil && tlsOptions == nil && options.ServerName == "" {
+		return nil, nil
+	}
+
+	var config *tls.Config
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		config = transport.TLSClientConfig.Clone()
+	} else {
+		config = &tls.Config{}
+	}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+	if tlsOptions != nil {
+		if err := tlsOptions.apply(config); err != nil {
+			return nil, err
+		}
+	}
+	if options.ServerName != "" {
+		config.ServerName = options.ServerName
+	}
+	return config, nil
+}
+
+func (c *Client) tlsOptions(options *RequestOptions) *TLSOptions {
+	if options.TLS != nil {
+		return options.TLS
+	}
+	return c.TLS
+}
+
+// usesTLS reports whether tlsConfig would return a configuration, without
+// loading certificates.
+func (c *Client) usesTLS(options *RequestOptions) bool {
+	return options.TLSCertificate != nil || options.TLSClientCert != "" || options.TLSClientKey != "" ||
+		c.TLSCertificate != nil || c.TLSClientCert != "" || c.TLSClientKey != "" ||
+		c.tlsOptions(options) != nil || options.ServerName != ""
+}
+
+// tlsKey identifies the TLS settings of a request by value, so requests that
+// build equal TLSOptions share a transport. Certificates and key log writers
+// are compared by pointer.
+func (c *Client) tlsKey(options *RequestOptions) string {
+	key := fmt.Sprintf("%s:%s:%p:%s:%s:%p", options.TLSClientCert, options.TLSClientKey, options.TLSCertificate,
+		c.TLSClientCert, c.TLSClientKey, c.TLSCertificate)
+	if o := c.tlsOptions(options); o != nil {
+		rootCAs := sha256.Sum256(o.RootCAs)
+		key += fmt.Sprintf(":%x:%s:%t:%d:%d:%v:%s:%v:%T%p", rootCAs, o.RootCAFile, o.InsecureSkipVerify,
+			o.MinVersion, o.MaxVersion, o.CipherSuites, o.ServerName, o.PinnedSPKI, o.KeyLogWriter, o.KeyLogWriter)
+	}
+	return key + ":" + options.ServerName
+}
+
+// clientCertificate resolves the mTLS client certificate. Request options
+// take precedence over the client, and a loaded TLSCertificate takes
+// precedence over TLSClientCert/TLSClientKey file paths.
+func (c *Client) clientCertificate(options *RequestOptions) (*tls.Certificate, error) {
+	switch {
+	case options.TLSCertificate != nil:
+		return options.TLSCertificate, nil
+	case options.TLSClientCert != "" || options.TLSClientKey != "":
+		return loadClientCertificate(options.TLSClientCert, options.TLSClientKey)
+	case c.TLSCertificate != nil:
+		return c.TLSCertificate, nil
+	case c.TLSClientCert != "" || c.TLSClientKey != "":
+		return loadClientCertificate(c.TLSClientCert, c.TLSClientKey)
+	}
+	return nil, nil
+}
+
+func loadClientCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("%w: TLSClientCert and TLSClientKey must be set together", ErrInvalidTLSConfig)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: loading client certificate: %w", ErrInvalidTLSConfig, err)
+	}
+	return &cert, nil
+}
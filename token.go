@@ -0,0 +1,163 @@
+package axios4go
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshBefore is how long before a JWT's exp claim a
+// TokenRefresher refreshes it when RefreshBefore is zero.
+const DefaultRefreshBefore = 30 * time.Second
+
+type TokenRefresher struct {
+	Refresh func() (string, error)
+	// RefreshBefore controls proactive refreshing of JWTs: when the current
+	// token is a JWT whose exp claim is less than RefreshBefore away, it is
+	// refreshed before the request is sent instead of waiting for a 401.
+	RefreshBefore time.Duration
+	// Clock, when set, replaces the system clock for checking JWT expiry.
+	Clock Clock
+
+	mu         sync.Mutex
+	token      string
+	refreshing *tokenRefresh
+}
+
+type tokenRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+func NewTokenRefresher(token string, refresh func() (string, error)) *TokenRefresher {
+	return &TokenRefresher{token: token, Refresh: refresh}
+}
+
+func (t *TokenRefresher) Token() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.token
+}
+
+func (t *TokenRefresher) SetToken(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.token = token
+}
+
+// freshToken returns the current token, refreshing it first when it is a
+// JWT that is about to expire. A failed proactive refresh keeps the current
+// token; the 401 retry still applies if the server rejects it.
+func (t *TokenRefresher) freshToken() string {
+	token := t.Token()
+	expiry, ok := JWTExpiry(token)
+	if !ok {
+		return token
+	}
+	refreshBefore := t.RefreshBefore
+	if refreshBefore <= 0 {
+		refreshBefore = DefaultRefreshBefore
+	}
+	if expiry.Sub(clockNow(t.Clock)) > refreshBefore {
+		return token
+	}
+	if err := t.refresh(token); err != nil {
+		return token
+	}
+	return t.Token()
+}
+
+// JWTExpiry returns the exp claim of a JWT. The signature is not verified;
+// ok is false when the token is not a JWT or has no exp claim.
+func JWTExpiry(token string) (expiry time.Time, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(exp)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+func (t *TokenRefresher) refresh(staleToken string) error {
+	t.mu.Lock()
+	if t.token != staleToken {
+		t.mu.Unlock()
+		return nil
+	}
+	if call := t.refreshing; call != nil {
+		t.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &tokenRefresh{done: make(chan struct{})}
+	t.refreshing = call
+	t.mu.Unlock()
+
+	var token string
+	var err error
+	if t.Refresh == nil {
+		err = errors.New("token refresher has no Refresh function")
+	} else {
+		token, err = t.Refresh()
+	}
+
+	t.mu.Lock()
+	if err == nil {
+		t.token = token
+	}
+	t.refreshing = nil
+	call.err = err
+	t.mu.Unlock()
+	close(call.done)
+
+	return err
+}
+
+func (t *TokenRefresher) retryUnauthorized(c *Client, options *RequestOptions, usedToken string, resp *Response, err error) (*Response, error) {
+	if !isUnauthorized(resp, err) {
+		return resp, err
+	}
+	if refreshErr := t.refresh(usedToken); refreshErr != nil {
+		return resp, err
+	}
+	info := c.retryInfo(options, 2, 2, "token refreshed after 401")
+	if logger := c.logger(); logger != nil {
+		c.logRetry(logger, info, options.LogLevel)
+	}
+	c.lifecycle().retrying(info)
+	resp, err = c.request(options)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		httpErr.Attempts = 2
+	}
+	return resp, err
+}
+
+func isUnauthorized(resp *Response, err error) bool {
+	if resp != nil {
+		return resp.StatusCode == http.StatusUnauthorized
+	}
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
+}
@@ -0,0 +1,425 @@
+package axios4go
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-ntlmssp"
+)
+
+// buildTransport returns the transport a request needs when its options
+// require something other than the client's own transport (a proxy, DNS
+// fallback, client certificates, a Unix socket, granular timeouts or NTLM),
+// or nil when the client's transport can be used as is. Transports are
+// cached on the client and never assigned to Client.HTTPClient, so
+// concurrent requests with different settings don't affect each other.
+func (c *Client) buildTransport(options *RequestOptions) (http.RoundTripper, error) {
+	var transport http.RoundTripper
+	switch c.Protocol {
+	case "", ProtocolHTTP1, ProtocolHTTP2:
+		connTransport, err := c.connectionTransport(options)
+		if err != nil {
+			return nil, err
+		}
+		if connTransport != nil {
+			transport = connTransport
+		}
+	case ProtocolHTTP3:
+		connTransport, err := c.connectionTransport(options)
+		if err != nil {
+			return nil, err
+		}
+		if connTransport != nil {
+			transport = connTransport
+		}
+		if transport, err = c.http3Transport(options, transport); err != nil {
+			return nil, err
+		}
+	case ProtocolH2C:
+		if options.hasProxy() || c.ProxyFunc != nil {
+			return nil, fmt.Errorf("%w: h2c cannot be used through a proxy", ErrUnsupportedProtocol)
+		}
+		transport = c.h2cTransport(c.unixSocket(options))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedProtocol, c.Protocol)
+	}
+
+	if options.Auth == nil || !options.Auth.usesNTLM() {
+		return transport, nil
+	}
+	if transport == nil {
+		transport = c.HTTPClient.Transport
+	}
+	return ntlmssp.Negotiator{RoundTripper: transport}, nil
+}
+
+// ownTransport returns the transport of a request that brings its own
+// HTTPClient or Transport, whose transport is base. It is used as is, since
+// the connection options are the caller's to configure, except for NTLM,
+// which needs to negotiate on its connections.
+func ownTransport(options *RequestOptions, base http.RoundTripper) http.RoundTripper {
+	transport := base
+	if options.Transport != nil {
+		transport = options.Transport
+	}
+	if options.Auth != nil && options.Auth.usesNTLM() {
+		return ntlmssp.Negotiator{RoundTripper: transport}
+	}
+	return transport
+}
+
+func (c *Client) connectionTransport(options *RequestOptions) (*http.Transport, error) {
+	for _, family := range []string{c.IPFamily, options.IPFamily} {
+		if !validIPFamily(family) {
+			return nil, fmt.Errorf("%w: unsupported IPFamily %q", ErrInvalidOption, family)
+		}
+	}
+
+	// A RoundTripper other than *http.Transport, such as a mock or an
+	// instrumenting wrapper, can't be reconfigured, so it is kept and the
+	// connection options are ignored rather than bypassing it.
+	if c.customTransport() {
+		return nil, nil
+	}
+
+	socket := c.unixSocket(options)
+	if !options.hasProxy() && !c.usesTLS(options) && !options.hasTransportTimeouts() && !options.hasAddressOverrides() {
+		switch {
+		case socket != "":
+			return c.socketTransport(socket), nil
+		case c.HTTPClient.Transport != nil:
+			if c.ProxyFunc == nil {
+				return nil, nil
+			}
+		case c.DialContext != nil || c.Resolver != nil || len(c.StaticDNS) > 0 || c.IPFamily != "" ||
+			c.ProxyFunc != nil || c.Protocol != "":
+			return c.sharedTransport(), nil
+		case c.DNSFallback != nil:
+			return c.DNSFallback.transport(), nil
+		default:
+			return nil, nil
+		}
+	}
+
+	transport, err := c.transports.load(c.transportKey(options, socket), func() (http.RoundTripper, error) {
+		return c.derivedTransport(options, socket)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transport.(*http.Transport), nil
+}
+
+// customTransport reports whether the client's transport is a RoundTripper
+// other than *http.Transport.
+func (c *Client) customTransport() bool {
+	if c.HTTPClient == nil || c.HTTPClient.Transport == nil {
+		return false
+	}
+	_, ok := c.HTTPClient.Transport.(*http.Transport)
+	return !ok
+}
+
+// derivedTransport builds a transport from the client's own for requests
+// whose options change how connections are made. Requests with equal
+// transport settings share it through the cache in connectionTransport, so
+// they also share pooled connections.
+func (c *Client) derivedTransport(options *RequestOptions, socket string) (*http.Transport, error) {
+	tlsConfig, err := c.tlsConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := c.baseTransport()
+	if options.ProxyFunc != nil {
+		transport.Proxy = requestProxy
+	} else if c.ProxyFunc != nil {
+		transport.Proxy = c.ProxyFunc
+	}
+	if options.Proxy != nil {
+		proxyURL, err := options.Proxy.url()
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+		transport.ProxyConnectHeader = options.Proxy.connectHeader()
+	}
+	if socket != "" {
+		transport.DialContext = unixDial(socket)
+	} else if options.hasAddressOverrides() {
+		transport.DialContext = c.requestAddressDial(options, c.baseDialContext())
+	} else if dial := c.dialContext(); dial != nil {
+		transport.DialContext = dial
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	applyTransportTimeouts(transport, options)
+	c.applyProtocol(transport)
+	return transport, nil
+}
+
+// transportKey identifies the transport settings derivedTransport uses.
+// Per-request ProxyFuncs are not part of it: they are looked up from the
+// request context, so one transport serves all of them.
+func (c *Client) transportKey(options *RequestOptions, socket string) string {
+	var key strings.Builder
+	fmt.Fprintf(&key, "derived|%s|%t", socket, options.ProxyFunc != nil)
+	if p := options.Proxy; p != nil {
+		auth := ""
+		if p.Auth != nil {
+			auth = p.Auth.Username + ":" + p.Auth.Password
+		}
+		fmt.Fprintf(&key, "|proxy:%s:%s:%d:%s:%s", p.Protocol, p.Host, p.Port, auth, sortedPairs(p.Headers))
+	}
+	if c.usesTLS(options) {
+		fmt.Fprintf(&key, "|tls:%s", c.tlsKey(options))
+	}
+	fmt.Fprintf(&key, "|timeouts:%d:%d:%d:%d", options.DialTimeout, options.TLSHandshakeTimeout,
+		options.ResponseHeaderTimeout, options.ExpectContinueTimeout)
+	fmt.Fprintf(&key, "|dns:%s:%s", sortedPairs(options.StaticDNS), options.IPFamily)
+	return key.String()
+}
+
+func sortedPairs(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (c *Client) baseTransport() *http.Transport {
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+		return transport.Clone()
+	}
+	return http.DefaultTransport.(*http.Transport).Clone()
+}
+
+// dialContext composes the client's dialing hooks: DialContext (or a default
+// net.Dialer) resolves through Resolver when set, DNSFallback retries failed
+// lookups on top of that, and StaticDNS and IPFamily pick the addresses to
+// dial. It returns nil when none are configured.
+func (c *Client) dialContext() DialContextFunc {
+	dial := c.baseDialContext()
+	if len(c.StaticDNS) == 0 && c.IPFamily == "" {
+		return dial
+	}
+	if dial == nil {
+		dial = defaultDialer().DialContext
+	}
+	return addressDial(c.StaticDNS, c.IPFamily, c.Resolver, dial)
+}
+
+func (c *Client) baseDialContext() DialContextFunc {
+	if c.DialContext == nil && c.Resolver == nil {
+		if c.DNSFallback != nil {
+			return c.DNSFallback.DialContext
+		}
+		return nil
+	}
+
+	dial := c.DialContext
+	if dial == nil {
+		dial = defaultDialer().DialContext
+	}
+	if c.Resolver != nil {
+		dial = resolvingDial(c.Resolver, dial)
+	}
+	if fallback := c.DNSFallback; fallback != nil {
+		next := dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return fallback.dial(ctx, next, network, addr)
+		}
+	}
+	return dial
+}
+
+// sharedTransport is the transport used when only client-level settings
+// (dialing hooks, ProxyFunc or Protocol) differ from the defaults, so
+// connections are pooled across requests. It is built on first use; later
+// changes to those settings do not affect it.
+func (c *Client) sharedTransport() *http.Transport {
+	return c.cachedTransport("shared", func() http.RoundTripper {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if dial := c.dialContext(); dial != nil {
+			transport.DialContext = dial
+		}
+		if c.ProxyFunc != nil {
+			transport.Proxy = c.ProxyFunc
+		}
+		c.applyProtocol(transport)
+		return transport
+	}).(*http.Transport)
+}
+
+func (c *Client) cachedTransport(key string, build func() http.RoundTripper) http.RoundTripper {
+	transport, _ := c.transports.load(key, func() (http.RoundTripper, error) {
+		return build(), nil
+	})
+	return transport
+}
+
+// maxCachedTransports bounds the transports a client keeps for distinct
+// proxy, TLS, DNS and timeout settings, so rotating per-request proxies
+// don't keep every transport and its idle connections forever.
+const maxCachedTransports = 32
+
+// transportCache holds the transports built for a client. When it is full,
+// the least recently used transport is dropped and its idle connections
+// closed; requests still using it finish normally.
+type transportCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type transportCacheItem struct {
+	key       string
+	transport http.RoundTripper
+}
+
+// load returns the transport cached under key, building and caching it on
+// a miss.
+func (c *transportCache) load(key string, build func() (http.RoundTripper, error)) (http.RoundTripper, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*transportCacheItem).transport, nil
+	}
+	c.mu.Unlock()
+
+	transport, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		closeIdleConnections(transport)
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*transportCacheItem).transport, nil
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+		c.lru = list.New()
+	}
+	c.entries[key] = c.lru.PushFront(&transportCacheItem{key: key, transport: transport})
+	for c.lru.Len() > maxCachedTransports {
+		item := c.lru.Remove(c.lru.Back()).(*transportCacheItem)
+		delete(c.entries, item.key)
+		closeIdleConnections(item.transport)
+	}
+	return transport, nil
+}
+
+func (c *transportCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func closeIdleConnections(transport http.RoundTripper) {
+	if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (o *RequestOptions) hasTransportTimeouts() bool {
+	return o.DialTimeout > 0 || o.TLSHandshakeTimeout > 0 || o.ResponseHeaderTimeout > 0 || o.ExpectContinueTimeout > 0
+}
+
+func applyTransportTimeouts(transport *http.Transport, options *RequestOptions) {
+	if options.DialTimeout > 0 {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		timeout := time.Duration(options.DialTimeout) * time.Millisecond
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return dial(ctx, network, addr)
+		}
+	}
+	if options.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = time.Duration(options.TLSHandshakeTimeout) * time.Millisecond
+	}
+	if options.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = time.Duration(options.ResponseHeaderTimeout) * time.Millisecond
+	}
+	if options.ExpectContinueTimeout > 0 {
+		transport.ExpectContinueTimeout = time.Duration(options.ExpectContinueTimeout) * time.Millisecond
+	}
+}
+
+func defaultDialer() *net.Dialer {
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+}
+
+func (o *RequestOptions) hasProxy() bool {
+	return o.Proxy != nil || o.ProxyFunc != nil
+}
+
+func (o *RequestOptions) hasAddressOverrides() bool {
+	return len(o.StaticDNS) > 0 || o.IPFamily != ""
+}
+
+// requestAddressDial applies per-request StaticDNS and IPFamily on top of the
+// client's, with request entries taking precedence.
+func (c *Client) requestAddressDial(options *RequestOptions, dial DialContextFunc) DialContextFunc {
+	staticDNS := make(map[string]string, len(c.StaticDNS)+len(options.StaticDNS))
+	for host, ip := range c.StaticDNS {
+		staticDNS[host] = ip
+	}
+	for host, ip := range options.StaticDNS {
+		staticDNS[host] = ip
+	}
+	family := options.IPFamily
+	if family == "" {
+		family = c.IPFamily
+	}
+	if dial == nil {
+		dial = defaultDialer().DialContext
+	}
+	return addressDial(staticDNS, family, c.Resolver, dial)
+}
+
+// url returns the proxy URL. Auth goes into its userinfo, from which net/http
+// sends Proxy-Authorization both on CONNECT and on every request forwarded
+// to a plain HTTP proxy, and authenticates to SOCKS5 proxies.
+func (p *Proxy) url() (*url.URL, error) {
+	proxyURL, err := url.Parse(fmt.Sprintf("%s://%s", p.Protocol, net.JoinHostPort(p.Host, strconv.Itoa(p.Port))))
+	if err != nil {
+		return nil, err
+	}
+	if p.Auth != nil {
+		proxyURL.User = url.UserPassword(p.Auth.Username, p.Auth.Password)
+	}
+	return proxyURL, nil
+}
+
+// connectHeader returns the headers sent with the CONNECT request that opens
+// a tunnel through the proxy, or nil when there are none.
+func (p *Proxy) connectHeader() http.Header {
+	if len(p.Headers) == 0 {
+		return nil
+	}
+	header := make(http.Header, len(p.Headers))
+	for key, value := range p.Headers {
+		header.Set(key, value)
+	}
+	return header
+}
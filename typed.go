@@ -0,0 +1,64 @@
+package axios4go
+
+import "fmt"
+
+// GetT sends a GET request with the default client and decodes the
+// response body into a T:
+//
+//	user, resp, err := axios4go.GetT[User]("https://api.example.com/users/1")
+//
+// JSON bodies are decoded with Response.JSON; a T of string or []byte gets
+// the body as is, and an empty body leaves T at its zero value. If the body
+// can't be decoded, the response is returned with the error.
+func GetT[T any](urlStr string, options ...*RequestOptions) (T, *Response, error) {
+	return decodeT[T](Get(urlStr, options...))
+}
+
+// PostT is Post, decoding the response body like GetT.
+func PostT[T any](urlStr string, body interface{}, options ...*RequestOptions) (T, *Response, error) {
+	return decodeT[T](Post(urlStr, body, options...))
+}
+
+// PutT is Put, decoding the response body like GetT.
+func PutT[T any](urlStr string, body interface{}, options ...*RequestOptions) (T, *Response, error) {
+	return decodeT[T](Put(urlStr, body, options...))
+}
+
+// PatchT is Patch, decoding the response body like GetT.
+func PatchT[T any](urlStr string, body interface{}, options ...*RequestOptions) (T, *Response, error) {
+	return decodeT[T](Patch(urlStr, body, options...))
+}
+
+// DeleteT is Delete, decoding the response body like GetT.
+func DeleteT[T any](urlStr string, options ...*RequestOptions) (T, *Response, error) {
+	return decodeT[T](Delete(urlStr, options...))
+}
+
+// RequestT sends a request with c and decodes the response body like GetT,
+// since Go methods can't have type parameters:
+//
+//	users, _, err := axios4go.RequestT[[]User](client, &axios4go.RequestOptions{URL: "/users"})
+func RequestT[T any](c *Client, options *RequestOptions) (T, *Response, error) {
+	return decodeT[T](c.Request(options))
+}
+
+func decodeT[T any](resp *Response, err error) (T, *Response, error) {
+	var v T
+	if err != nil {
+		return v, resp, err
+	}
+	switch p := interface{}(&v).(type) {
+	case *string:
+		*p = string(resp.Body)
+	case *[]byte:
+		*p = resp.Body
+	default:
+		if len(resp.Body) == 0 {
+			return v, resp, nil
+		}
+		if err := resp.JSON(&v); err != nil {
+			return v, resp, fmt.Errorf("decoding response body: %w", err)
+		}
+	}
+	return v, resp, nil
+}
@@ -0,0 +1,49 @@
+package axios4go
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const unixScheme = "unix://"
+
+// unixSocketHost replaces a unix:// base URL in request URLs. The host is
+// only used for the Host header; the connection goes to the socket.
+const unixSocketHost = "http://localhost"
+
+// unixSocket returns the Unix domain socket a request should be sent over:
+// RequestOptions.UnixSocket, then Client.UnixSocket, then the path of a
+// unix:// base URL.
+func (c *Client) unixSocket(options *RequestOptions) string {
+	switch {
+	case options.UnixSocket != "":
+		return options.UnixSocket
+	case c.UnixSocket != "":
+		return c.UnixSocket
+	}
+	if base := c.baseURL(options); strings.HasPrefix(base, unixScheme) {
+		return strings.TrimPrefix(base, unixScheme)
+	}
+	return ""
+}
+
+func unixDial(socket string) DialContextFunc {
+	dialer := &net.Dialer{}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+}
+
+// socketTransport returns a pooled transport that sends every request over
+// the given socket.
+func (c *Client) socketTransport(socket string) *http.Transport {
+	return c.cachedTransport("unix:"+socket, func() http.RoundTripper {
+		transport := c.baseTransport()
+		transport.Proxy = nil
+		transport.DialContext = unixDial(socket)
+		c.applyProtocol(transport)
+		return transport
+	}).(*http.Transport)
+}
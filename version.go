@@ -0,0 +1,9 @@
+package axios4go
+
+// Version is the version of axios4go. Release-please updates it with
+// every release.
+const Version = "0.6.2" // x-release-please-version
+
+// DefaultUserAgent is the User-Agent sent by requests that don't set one,
+// so servers can tell which library sent them.
+const DefaultUserAgent = "axios4go/" + Version
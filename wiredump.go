@@ -0,0 +1,83 @@
+package axios4go
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+const defaultWireDumpMaxBytes = 64 << 10
+
+// WireDump captures the raw request and response, as
+// httputil.DumpRequestOut and httputil.DumpResponse render them, of requests
+// that fail or take longer than SlowThreshold. The dump is attached to the
+// returned *RequestError or *HTTPError as WireDump and, if Output is set,
+// written there. Dumps are cut off after MaxBytes (64 KiB by default) and
+// credential headers are masked.
+type WireDump struct {
+	SlowThreshold time.Duration
+	MaxBytes      int
+	Output        io.Writer
+
+	mu sync.Mutex
+}
+
+func (d *WireDump) slow(duration time.Duration) bool {
+	return d.SlowThreshold > 0 && duration > d.SlowThreshold
+}
+
+// capture dumps req, sent with body, and resp, read into respBody, or only
+// req if resp is nil.
+func (d *WireDump) capture(req *http.Request, body []byte, resp *http.Response, respBody []byte, masked []string) string {
+	var buf bytes.Buffer
+
+	sent := req.Clone(req.Context())
+	sent.Header = maskHeaderValues(req.Header, masked)
+	sent.URL = maskParams(req.URL, maskedParams(req.Context()))
+	sent.Body = nil
+	if body != nil {
+		sent.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if dump, err := httputil.DumpRequestOut(sent, true); err == nil {
+		buf.Write(dump)
+	}
+
+	if resp != nil {
+		received := *resp
+		received.Header = maskHeaderValues(resp.Header, masked)
+		if dump, err := httputil.DumpResponse(&received, false); err == nil {
+			buf.WriteString("\n")
+			buf.Write(dump)
+			buf.Write(respBody)
+		}
+	}
+
+	maxBytes := d.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultWireDumpMaxBytes
+	}
+	dump := buf.String()
+	if len(dump) > maxBytes {
+		dump = dump[:maxBytes] + "\n... (truncated)"
+	}
+
+	if d.Output != nil {
+		d.mu.Lock()
+		io.WriteString(d.Output, dump+"\n")
+		d.mu.Unlock()
+	}
+	return dump
+}
+
+func maskHeaderValues(header http.Header, masked []string) http.Header {
+	clone := header.Clone()
+	for _, name := range masked {
+		if clone.Get(name) != "" {
+			clone.Set(name, "[MASKED]")
+		}
+	}
+	return clone
+}